@@ -28,3 +28,23 @@ type Location struct {
 	URI   DocumentURI `json:"uri"`
 	Range Range       `json:"range"`
 }
+
+//Before reports whether position `p` comes strictly before `other` in a document
+func (p Position) Before(other Position) bool {
+	return p.Line < other.Line || (p.Line == other.Line && p.Character < other.Character)
+}
+
+//Contains reports whether position `p` lies within the range. The end position is exclusive
+func (r Range) Contains(p Position) bool {
+	return !p.Before(r.Start) && p.Before(r.End)
+}
+
+//Overlaps reports whether the two ranges intersect. Ranges that merely touch are considered overlapping
+func (r Range) Overlaps(other Range) bool {
+	return !r.End.Before(other.Start) && !other.End.Before(r.Start)
+}
+
+//ContainsRange reports whether `other` lies entirely within the range, which may share its start or end
+func (r Range) ContainsRange(other Range) bool {
+	return !other.Start.Before(r.Start) && !r.End.Before(other.End)
+}
@@ -0,0 +1,102 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+//UTF16Len returns the length of `s` in UTF-16 code units, which is the unit `Position.Character` is measured in
+func UTF16Len(s string) int64 {
+	var n int64
+	for _, r := range s {
+		if r >= 0x10000 && r <= utf8.MaxRune {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+//lineEnd returns the end of the line starting at byte `start` of `text`, and the start of the next line, or -1 if it is the last line.
+//Lines end with "\n", "\r\n" or a lone "\r", as the LSP specifies
+func lineEnd(text string, start int) (end, next int) {
+	i := strings.IndexAny(text[start:], "\r\n")
+	if i < 0 {
+		return len(text), -1
+	}
+	end = start + i
+	if text[end] == '\r' && end+1 < len(text) && text[end+1] == '\n' {
+		return end, end + 2
+	}
+	return end, end + 1
+}
+
+//Lines splits `text` into lines without their line terminators, so that line i of the result is line i of positions in `text`.
+//Lines end with "\n", "\r\n" or a lone "\r"
+func Lines(text string) []string {
+	lines := []string{}
+	for start := 0; ; {
+		end, next := lineEnd(text, start)
+		lines = append(lines, text[start:end])
+		if next < 0 {
+			return lines
+		}
+		start = next
+	}
+}
+
+//PositionAt returns the position of the byte `offset` in `text`. Offsets past the end of the text map to the end of the text.
+//Lines end with "\n", "\r\n" or a lone "\r"
+func PositionAt(text string, offset int) Position {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	line, lineStart := 0, 0
+	for {
+		_, next := lineEnd(text, lineStart)
+		if next < 0 || next > offset {
+			break
+		}
+		line++
+		lineStart = next
+	}
+	return Position{Line: int64(line), Character: UTF16Len(text[lineStart:offset])}
+}
+
+//OffsetAt returns the byte offset of position `p` in `text`. A character offset past the end of its line maps to the end of the line
+//(before any line terminator), as the LSP specifies. Lines end with "\n", "\r\n" or a lone "\r".
+//The line just past the last line maps to the end of the text. It is an error for the line to be further past the end of the text, or for the position to fall between the two UTF-16 code units of a single character
+func OffsetAt(text string, p Position) (int, error) {
+	if p.Line < 0 || p.Character < 0 {
+		return 0, fmt.Errorf("invalid position %d:%d", p.Line, p.Character)
+	}
+	offset := 0
+	for line := int64(0); line < p.Line; line++ {
+		_, next := lineEnd(text, offset)
+		if next < 0 && line+1 == p.Line {
+			//the line after the last one, as in the range of an edit appending to a text without a final line terminator
+			return len(text), nil
+		}
+		if next < 0 {
+			return 0, fmt.Errorf("line %d is past the end of the text", p.Line)
+		}
+		offset = next
+	}
+	end, _ := lineEnd(text, offset)
+	var units int64
+	for i, r := range text[offset:end] {
+		if units == p.Character {
+			return offset + i, nil
+		}
+		if units > p.Character {
+			return 0, fmt.Errorf("position %d:%d splits a character", p.Line, p.Character)
+		}
+		units += UTF16Len(string(r))
+	}
+	if units > p.Character {
+		return 0, fmt.Errorf("position %d:%d splits a character", p.Line, p.Character)
+	}
+	return end, nil
+}
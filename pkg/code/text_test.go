@@ -0,0 +1,118 @@
+package code
+
+import "testing"
+
+func TestPositionAtLineEndings(t *testing.T) {
+	text := "a\nb\r\nc\rd"
+	tests := []struct {
+		offset int
+		want   Position
+	}{
+		{offset: 0, want: Position{Line: 0, Character: 0}},
+		{offset: 1, want: Position{Line: 0, Character: 1}},
+		{offset: 2, want: Position{Line: 1, Character: 0}},
+		{offset: 3, want: Position{Line: 1, Character: 1}},
+		{offset: 5, want: Position{Line: 2, Character: 0}},
+		{offset: 6, want: Position{Line: 2, Character: 1}},
+		{offset: 7, want: Position{Line: 3, Character: 0}},
+		{offset: 8, want: Position{Line: 3, Character: 1}},
+		{offset: 100, want: Position{Line: 3, Character: 1}},
+	}
+	for _, tt := range tests {
+		if got := PositionAt(text, tt.offset); got != tt.want {
+			t.Errorf("PositionAt(%q, %d) = %v, want %v", text, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: []string{""}},
+		{text: "a", want: []string{"a"}},
+		{text: "a\n", want: []string{"a", ""}},
+		{text: "a\nb\r\nc\rd", want: []string{"a", "b", "c", "d"}},
+		{text: "\r\r\n\n", want: []string{"", "", "", ""}},
+	}
+	for _, tt := range tests {
+		got := Lines(tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("Lines(%q) = %q, want %q", tt.text, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Lines(%q) = %q, want %q", tt.text, got, tt.want)
+				break
+			}
+		}
+		if last := PositionAt(tt.text, len(tt.text)); last.Line != int64(len(got)-1) {
+			t.Errorf("Lines(%q) has %d lines, but the text ends on line %d", tt.text, len(got), last.Line)
+		}
+	}
+}
+
+func TestOffsetAtLineEndings(t *testing.T) {
+	text := "a\nb\r\nc\rd"
+	tests := []struct {
+		position Position
+		want     int
+	}{
+		{position: Position{Line: 0, Character: 5}, want: 1},
+		{position: Position{Line: 1, Character: 0}, want: 2},
+		{position: Position{Line: 1, Character: 5}, want: 3},
+		{position: Position{Line: 2, Character: 0}, want: 5},
+		{position: Position{Line: 2, Character: 1}, want: 6},
+		{position: Position{Line: 2, Character: 5}, want: 6},
+		{position: Position{Line: 3, Character: 0}, want: 7},
+		{position: Position{Line: 3, Character: 1}, want: 8},
+	}
+	for _, tt := range tests {
+		got, err := OffsetAt(text, tt.position)
+		if err != nil || got != tt.want {
+			t.Errorf("OffsetAt(%q, %v) = %d, %v, want %d", text, tt.position, got, err, tt.want)
+		}
+		if err == nil && tt.position.Character == 0 {
+			if back := PositionAt(text, got); back != tt.position {
+				t.Errorf("PositionAt(%q, %d) = %v, want %v", text, got, back, tt.position)
+			}
+		}
+	}
+}
+
+func TestOffsetAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		position Position
+		want     int
+		err      bool
+	}{
+		{name: "empty text", text: "", position: Position{Line: 0, Character: 0}, want: 0},
+		{name: "line after empty text", text: "", position: Position{Line: 1, Character: 0}, want: 0},
+		{name: "line after the last line", text: "abc", position: Position{Line: 1, Character: 0}, want: 3},
+		{name: "line after a final newline", text: "abc\n", position: Position{Line: 1, Character: 0}, want: 4},
+		{name: "line after the empty last line", text: "abc\n", position: Position{Line: 2, Character: 0}, want: 4},
+		{name: "line far past the end", text: "abc", position: Position{Line: 2, Character: 0}, err: true},
+		{name: "negative", text: "abc", position: Position{Line: 0, Character: -1}, err: true},
+		{name: "non-BMP character", text: "a😀b", position: Position{Line: 0, Character: 3}, want: 5},
+		{name: "inside a surrogate pair", text: "a😀b", position: Position{Line: 0, Character: 2}, err: true},
+		{name: "past a final non-BMP character", text: "a😀", position: Position{Line: 0, Character: 9}, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OffsetAt(tt.text, tt.position)
+			if tt.err {
+				if err == nil {
+					t.Errorf("got offset %d, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
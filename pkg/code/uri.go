@@ -0,0 +1,35 @@
+package code
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+//URIFromPath returns the `file` URI of an absolute file system path
+func URIFromPath(path string) DocumentURI {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		//Windows paths such as C:/dir start with a drive letter
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "file", Path: path}
+	return DocumentURI(u.String())
+}
+
+//Filename returns the file system path of a `file` URI
+func (uri DocumentURI) Filename() (string, error) {
+	u, err := url.Parse(string(uri))
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("%q is not a file URI", uri)
+	}
+	path := u.Path
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
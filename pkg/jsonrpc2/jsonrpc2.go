@@ -47,6 +47,11 @@ type Error struct {
 	Data    *json.RawMessage `json:"data"`
 }
 
+//Error implements the error interface so that a JSON RPC 2.0 Error can be returned by handlers
+func (e *Error) Error() string {
+	return e.Message
+}
+
 //ID is a Request identifier, which is either a number or string
 type ID struct {
 	NumberID int64
@@ -6,6 +6,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -29,8 +30,9 @@ func NewStream(in io.Reader, out io.Writer) Stream {
 }
 
 type protocolStream struct {
-	in  *bufio.Reader
-	out io.Writer
+	in       *bufio.Reader
+	out      io.Writer
+	outMutex sync.Mutex //serialises writes of messages sent from concurrent goroutines
 }
 
 func (ps *protocolStream) Read() (data []byte, length int64, err error) {
@@ -39,6 +41,10 @@ func (ps *protocolStream) Read() (data []byte, length int64, err error) {
 		line, err := ps.in.ReadString('\n')
 		total += int64(len(line))
 		if err != nil {
+			if err == io.EOF && total == 0 {
+				//the stream was closed between messages
+				return data, total, io.EOF
+			}
 			return data, total, fmt.Errorf("Error reading header %q", err)
 		}
 		line = strings.TrimSpace(line)
@@ -74,6 +80,8 @@ func (ps *protocolStream) Read() (data []byte, length int64, err error) {
 }
 
 func (ps *protocolStream) Write(data []byte) (int64, error) {
+	ps.outMutex.Lock()
+	defer ps.outMutex.Unlock()
 	n, err := fmt.Fprintf(ps.out, "%s: %v\r\n\r\n", headerLengthPrefix, len(data))
 	total := int64(n)
 	if err == nil {
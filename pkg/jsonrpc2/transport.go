@@ -80,6 +80,31 @@ func (dt *DefaultTransport) SendNotification(method string, data interface{}) er
 	return nil
 }
 
+//SendRequest constructs a JSON RPC 2.0 Request with the given `id`, `method` and `data` (converted to JSON raw message, omitted if nil)
+//over a Stream returning an error as may be necessary
+func (dt *DefaultTransport) SendRequest(id *ID, method string, data interface{}) error {
+	request := Request{
+		Version: VersionTag{},
+		ID:      id,
+		Method:  method,
+	}
+	if data != nil { //params are omitted rather than sent as null
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		rawMessage := json.RawMessage(raw)
+		request.Params = &rawMessage
+	}
+	outBytes, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	_, err = dt.io.Write(outBytes)
+	return err
+}
+
 //SendErrorResponse sends an error `errX` over some Stream in response to an error associated with the response identified by `id`
 func (dt *DefaultTransport) SendErrorResponse(id *ID, errX *Error) error {
 	response := Response{
@@ -0,0 +1,71 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+//ApplyWorkspaceEditParams are the parameters of a `workspace/applyEdit` request sent from the server to the client
+type ApplyWorkspaceEditParams struct {
+	//Label is an optional label of the workspace edit, presented in the user interface, for example on an undo stack
+	Label *string `json:"label,omitempty"`
+	//Edit is the edit to apply
+	Edit WorkspaceEdit `json:"edit"`
+}
+
+//ApplyWorkspaceEditResponse is the result of a `workspace/applyEdit` request
+type ApplyWorkspaceEditResponse struct {
+	//Applied indicates whether the edit was applied or not
+	Applied bool `json:"applied"`
+	//FailureReason is an optional textual description of why the edit was not applied
+	FailureReason *string `json:"failureReason,omitempty"`
+	//FailedChange is the index of the failed change, when the client signals a `failureHandling` strategy
+	FailedChange *int64 `json:"failedChange,omitempty"`
+}
+
+//ApplyEditError reports a workspace edit that the client did not apply
+type ApplyEditError struct {
+	//FailureReason as given by the client, possibly empty
+	FailureReason string
+	//FailedChange is the index of the document change that failed, or nil if the client did not say
+	FailedChange *int64
+}
+
+func (e *ApplyEditError) Error() string {
+	msg := "workspace edit was not applied"
+	if e.FailedChange != nil {
+		msg = fmt.Sprintf("%s: change %d failed", msg, *e.FailedChange)
+	}
+	if e.FailureReason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.FailureReason)
+	}
+	return msg
+}
+
+//errApplyEditUnsupported is returned when the client did not declare support for `workspace/applyEdit`
+var errApplyEditUnsupported = errors.New("client does not support workspace/applyEdit")
+
+//ApplyEdit asks the client to apply `edit` to the workspace, using `label` (if not empty) to describe it to the user.
+//It returns an *ApplyEditError if the client could not apply the edit. Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) ApplyEdit(ctx context.Context, label string, edit WorkspaceEdit) error {
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc == nil || wc.ApplyEdit == nil || !*wc.ApplyEdit {
+		return errApplyEditUnsupported
+	}
+	params := ApplyWorkspaceEditParams{Edit: edit}
+	if label != "" {
+		params.Label = &label
+	}
+	response := ApplyWorkspaceEditResponse{}
+	if err := s.Call(ctx, "workspace/applyEdit", params, &response); err != nil {
+		return err
+	}
+	if !response.Applied {
+		applyErr := ApplyEditError{FailedChange: response.FailedChange}
+		if response.FailureReason != nil {
+			applyErr.FailureReason = *response.FailureReason
+		}
+		return &applyErr
+	}
+	return nil
+}
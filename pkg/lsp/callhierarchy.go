@@ -0,0 +1,152 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//CallHierarchyPrepareParams are the parameters of a `textDocument/prepareCallHierarchy` request
+type CallHierarchyPrepareParams struct {
+	TextDocumentPositionParams
+}
+
+//CallHierarchyItem is a node of the call hierarchy, such as a function or method
+type CallHierarchyItem struct {
+	//Name of this item
+	Name string `json:"name"`
+	//Kind of this item
+	Kind SymbolKind `json:"kind"`
+	//Tags for this item
+	Tags []SymbolTag `json:"tags,omitempty"`
+	//Detail of this item, e.g. the signature of a function
+	Detail *string `json:"detail,omitempty"`
+	//URI is the resource identifier of this item
+	URI code.DocumentURI `json:"uri"`
+	//Range enclosing this symbol not including leading/trailing whitespace but everything else, e.g. comments and code
+	Range code.Range `json:"range"`
+	//SelectionRange is the range that should be selected and revealed when this symbol is being picked, e.g. the name of a function.
+	//Must be contained by `range`
+	SelectionRange code.Range `json:"selectionRange"`
+	//Data is preserved between a call hierarchy prepare and incoming calls or outgoing calls requests
+	Data *json.RawMessage `json:"data,omitempty"`
+}
+
+//CallHierarchyIncomingCallsParams are the parameters of a `callHierarchy/incomingCalls` request
+type CallHierarchyIncomingCallsParams struct {
+	Item CallHierarchyItem `json:"item"`
+}
+
+//CallHierarchyIncomingCall is a call made to an item of the call hierarchy
+type CallHierarchyIncomingCall struct {
+	//From is the item that makes the call
+	From CallHierarchyItem `json:"from"`
+	//FromRanges are the ranges at which the calls appear. They are relative to the caller denoted by `from`
+	FromRanges []code.Range `json:"fromRanges"`
+}
+
+//CallHierarchyOutgoingCallsParams are the parameters of a `callHierarchy/outgoingCalls` request
+type CallHierarchyOutgoingCallsParams struct {
+	Item CallHierarchyItem `json:"item"`
+}
+
+//CallHierarchyOutgoingCall is a call made from an item of the call hierarchy
+type CallHierarchyOutgoingCall struct {
+	//To is the item that is called
+	To CallHierarchyItem `json:"to"`
+	//FromRanges are the ranges at which this item is called. They are relative to the caller, i.e. the item passed to the request
+	FromRanges []code.Range `json:"fromRanges"`
+}
+
+//CallHierarchyOptions are the server capabilities for call hierarchies
+type CallHierarchyOptions struct {
+	*WorkDoneProgressOptions
+}
+
+//CallHierarchyRegistrationOptions are the registration options for call hierarchies
+type CallHierarchyRegistrationOptions struct {
+	*TextDocumentRegistrationOptions
+	*CallHierarchyOptions
+	*StaticRegistrationOptions
+}
+
+type callHierarchyUnion struct {
+	Boolean             *bool
+	Options             *CallHierarchyOptions
+	RegistrationOptions *CallHierarchyRegistrationOptions
+}
+
+func (cu *callHierarchyUnion) MarshalJSON() ([]byte, error) {
+	if cu.Boolean != nil {
+		return json.Marshal(*cu.Boolean)
+	}
+	if cu.Options != nil {
+		return json.Marshal(*cu.Options)
+	}
+	return json.Marshal(cu.RegistrationOptions)
+}
+
+func (cu *callHierarchyUnion) UnmarshalJSON(js []byte) error {
+	*cu = callHierarchyUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		cu.Boolean = &b
+		return nil
+	}
+	cu.RegistrationOptions = &CallHierarchyRegistrationOptions{}
+	return json.Unmarshal(js, cu.RegistrationOptions)
+}
+
+//CallHierarchyProvider is implemented by embedding servers that provide call hierarchies. The `Data` of the items returned by
+//`PrepareCallHierarchy` is sent back unchanged with the items of the incoming and outgoing calls requests
+type CallHierarchyProvider interface {
+	PrepareCallHierarchy(params *CallHierarchyPrepareParams) ([]CallHierarchyItem, error)
+	IncomingCalls(params *CallHierarchyIncomingCallsParams) ([]CallHierarchyIncomingCall, error)
+	OutgoingCalls(params *CallHierarchyOutgoingCallsParams) ([]CallHierarchyOutgoingCall, error)
+}
+
+func (s *DefaultServer) prepareCallHierarchy(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(CallHierarchyProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := CallHierarchyPrepareParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	items, err := provider.PrepareCallHierarchy(&params)
+	s.reply(req, items, err)
+}
+
+func (s *DefaultServer) incomingCalls(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(CallHierarchyProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := CallHierarchyIncomingCallsParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	calls, err := provider.IncomingCalls(&params)
+	s.reply(req, calls, err)
+}
+
+func (s *DefaultServer) outgoingCalls(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(CallHierarchyProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := CallHierarchyOutgoingCallsParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	calls, err := provider.OutgoingCalls(&params)
+	s.reply(req, calls, err)
+}
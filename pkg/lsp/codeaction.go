@@ -0,0 +1,323 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//CodeActionKind is the kind of a code action. Kinds are a hierarchical list of identifiers separated by `.`,
+//e.g. `"refactor.extract.function"`
+type CodeActionKind string
+
+const (
+	//CodeActionKindEmpty is the empty kind
+	CodeActionKindEmpty CodeActionKind = ""
+	//CodeActionKindQuickFix is the base kind for quickfix actions
+	CodeActionKindQuickFix CodeActionKind = "quickfix"
+	//CodeActionKindRefactor is the base kind for refactoring actions
+	CodeActionKindRefactor CodeActionKind = "refactor"
+	//CodeActionKindRefactorExtract is the base kind for refactoring extraction actions, e.g. extract method or variable
+	CodeActionKindRefactorExtract CodeActionKind = "refactor.extract"
+	//CodeActionKindRefactorInline is the base kind for refactoring inline actions, e.g. inline function or constant
+	CodeActionKindRefactorInline CodeActionKind = "refactor.inline"
+	//CodeActionKindRefactorRewrite is the base kind for refactoring rewrite actions, e.g. convert to arrow function
+	CodeActionKindRefactorRewrite CodeActionKind = "refactor.rewrite"
+	//CodeActionKindSource is the base kind for source actions, which apply to the entire file
+	CodeActionKindSource CodeActionKind = "source"
+	//CodeActionKindSourceOrganizeImports is the base kind for an organize imports source action
+	CodeActionKindSourceOrganizeImports CodeActionKind = "source.organizeImports"
+	//CodeActionKindSourceFixAll is the base kind for an auto-fix source action
+	CodeActionKindSourceFixAll CodeActionKind = "source.fixAll"
+)
+
+//Contains reports whether `kind` is the same as, or a sub-kind of `k`, e.g. `refactor` contains `refactor.extract`.
+//The empty kind contains every kind
+func (k CodeActionKind) Contains(kind CodeActionKind) bool {
+	return k == CodeActionKindEmpty || k == kind || strings.HasPrefix(string(kind), string(k)+".")
+}
+
+//Command represents a reference to a command
+type Command struct {
+	//Title of the command, like `save`
+	Title string `json:"title"`
+	//Command is the identifier of the actual command handler
+	Command string `json:"command"`
+	//Arguments that the command handler should be invoked with
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+//CodeActionParams are the parameters of a `textDocument/codeAction` request
+type CodeActionParams struct {
+	//The document in which the command was invoked
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//The range for which the command was invoked
+	Range code.Range `json:"range"`
+	//Context carrying additional information
+	Context CodeActionContext `json:"context"`
+}
+
+//CodeActionContext contains additional diagnostic information about the context in which a code action is run
+type CodeActionContext struct {
+	//Diagnostics is an array of diagnostics known on the client side overlapping the range provided to the
+	//`textDocument/codeAction` request
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	//Only holds the requested kinds of actions to return. Actions not of this kind are filtered out by the client before being shown
+	Only []CodeActionKind `json:"only,omitempty"`
+}
+
+//Requests reports whether `kind` was requested by the client. All kinds are requested if `Only` is empty
+func (ctx CodeActionContext) Requests(kind CodeActionKind) bool {
+	if len(ctx.Only) == 0 {
+		return true
+	}
+	for _, only := range ctx.Only {
+		if only.Contains(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+//DiagnosticsIn returns the diagnostics of the context whose range overlaps `r`
+func (ctx CodeActionContext) DiagnosticsIn(r code.Range) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, d := range ctx.Diagnostics {
+		if d.Range.Overlaps(r) {
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return diagnostics
+}
+
+//CodeAction represents a change that can be performed in code, e.g. to fix a problem or to refactor code.
+//A CodeAction must set either `edit` and/or a `command`. If both are supplied, the `edit` is applied first, then the `command` is executed
+type CodeAction struct {
+	//Title is a short, human-readable, title for this code action
+	Title string `json:"title"`
+	//Kind of the code action. Used to filter code actions
+	Kind *CodeActionKind `json:"kind,omitempty"`
+	//Diagnostics that this code action resolves
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	//IsPreferred marks this as a preferred action. Preferred actions are used by the `auto fix` command and can be targeted by keybindings
+	IsPreferred *bool `json:"isPreferred,omitempty"`
+	//Disabled marks that the code action cannot currently be applied, with a reason shown to the user
+	Disabled *CodeActionDisabled `json:"disabled,omitempty"`
+	//Edit is the workspace edit this code action performs
+	Edit *WorkspaceEdit `json:"edit,omitempty"`
+	//Command that is executed after the edit is applied, if any
+	Command *Command `json:"command,omitempty"`
+	//Data is preserved between a `textDocument/codeAction` and a `codeAction/resolve` request
+	Data *json.RawMessage `json:"data,omitempty"`
+}
+
+//CodeActionDisabled carries the reason why a code action is disabled
+type CodeActionDisabled struct {
+	//Reason is a human readable description of why the code action is currently disabled
+	Reason string `json:"reason"`
+}
+
+//NewQuickFix creates a quickfix code action that applies `edit` and resolves the given `diagnostics`
+func NewQuickFix(title string, edit *WorkspaceEdit, diagnostics ...Diagnostic) CodeAction {
+	kind := CodeActionKindQuickFix
+	action := CodeAction{
+		Title: title,
+		Kind:  &kind,
+		Edit:  edit,
+	}
+	return *action.Fixes(diagnostics...)
+}
+
+//Fixes associates the code action with the diagnostics it resolves
+func (ca *CodeAction) Fixes(diagnostics ...Diagnostic) *CodeAction {
+	ca.Diagnostics = append(ca.Diagnostics, diagnostics...)
+	return ca
+}
+
+//Prefer marks the code action as the preferred action for its diagnostics
+func (ca *CodeAction) Prefer() *CodeAction {
+	preferred := true
+	ca.IsPreferred = &preferred
+	return ca
+}
+
+//Disable marks the code action as disabled with the given reason
+func (ca *CodeAction) Disable(reason string) *CodeAction {
+	ca.Disabled = &CodeActionDisabled{Reason: reason}
+	return ca
+}
+
+//CodeActionOptions are the server capabilities for code actions
+type CodeActionOptions struct {
+	*WorkDoneProgressOptions
+	//CodeActionKinds that this server may return
+	CodeActionKinds []CodeActionKind `json:"codeActionKinds,omitempty"`
+	//ResolveProvider indicates that the server provides support to resolve additional information for a code action
+	ResolveProvider *bool `json:"resolveProvider,omitempty"`
+}
+
+type codeActionUnion struct {
+	Boolean *bool
+	Options *CodeActionOptions
+}
+
+func (cu *codeActionUnion) MarshalJSON() ([]byte, error) {
+	if cu.Boolean != nil {
+		return json.Marshal(*cu.Boolean)
+	}
+	return json.Marshal(cu.Options)
+}
+
+func (cu *codeActionUnion) UnmarshalJSON(js []byte) error {
+	*cu = codeActionUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		cu.Boolean = &b
+		return nil
+	}
+	cu.Options = &CodeActionOptions{}
+	return json.Unmarshal(js, cu.Options)
+}
+
+//CodeActionProvider is implemented by embedding servers that compute code actions for `textDocument/codeAction`
+type CodeActionProvider interface {
+	//CodeActionKinds returns the kinds of code actions the server may return, or nil if unknown
+	CodeActionKinds() []CodeActionKind
+	//CodeActions returns the code actions available for the given document range and context
+	CodeActions(params *CodeActionParams) ([]CodeAction, error)
+}
+
+//CodeActionResolver is optionally implemented by a `CodeActionProvider` to compute the edit of a code action
+//lazily on `codeAction/resolve`
+type CodeActionResolver interface {
+	ResolveCodeAction(action *CodeAction) (*CodeAction, error)
+}
+
+func (s *DefaultServer) codeActionCapability(p CodeActionProvider) *codeActionUnion {
+	caps := s.ClientCapabilities().TextDocumentCapabilities
+	if !s.supportsCodeActionLiterals() {
+		//options with code action kinds may only be used if the client supports code action literals
+		supported := true
+		return &codeActionUnion{Boolean: &supported}
+	}
+	options := CodeActionOptions{
+		CodeActionKinds: p.CodeActionKinds(),
+	}
+	if _, ok := p.(CodeActionResolver); ok && caps.CodeAction.ResolveSupport != nil {
+		resolve := true
+		options.ResolveProvider = &resolve
+	}
+	return &codeActionUnion{Options: &options}
+}
+
+func (s *DefaultServer) codeAction(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(CodeActionProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := CodeActionParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	actions, err := provider.CodeActions(&params)
+	if err != nil {
+		s.reply(req, nil, err)
+		return
+	}
+	s.reply(req, s.adaptCodeActions(params.Context, actions), nil)
+}
+
+//applyCodeActionEditCommand is the command registered by the server to apply the edits of code actions for clients without
+//code action literal support. Its arguments are the title of the action, its edit and the command to run after the edit, if any
+const applyCodeActionEditCommand = "go-lsp.applyCodeActionEdit"
+
+//registerCodeActionEditCommand registers `applyCodeActionEditCommand` if the client can only receive bare commands in response
+//to `textDocument/codeAction` but supports `workspace/applyEdit`, so that code actions with edits can still be offered to it
+func (s *DefaultServer) registerCodeActionEditCommand() {
+	if _, ok := s.provider().(CodeActionProvider); !ok || s.supportsCodeActionLiterals() {
+		return
+	}
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc == nil || wc.ApplyEdit == nil || !*wc.ApplyEdit {
+		return
+	}
+	if _, exists := s.commands[applyCodeActionEditCommand]; exists {
+		return
+	}
+	s.RegisterCommand(applyCodeActionEditCommand, func(ctx context.Context, title string, edit WorkspaceEdit, then *Command) (interface{}, error) {
+		if err := s.ApplyEdit(ctx, title, edit); err != nil {
+			return nil, err
+		}
+		if then == nil {
+			return nil, nil
+		}
+		return s.runCommand(ctx, *then)
+	})
+}
+
+func (s *DefaultServer) supportsCodeActionLiterals() bool {
+	tdc := s.ClientCapabilities().TextDocumentCapabilities
+	return tdc != nil && tdc.CodeAction != nil && tdc.CodeAction.CodeActionLiteralSupport != nil
+}
+
+//adaptCodeActions filters the actions by the kinds requested in the context and tailors them to the client's capabilities.
+//Clients without code action literal support receive the bare commands of the actions. Actions with an edit are turned into
+//a command applying the edit with `workspace/applyEdit` and then running the action's command. If the client does not support
+//`workspace/applyEdit` either, or the action's edit is only computed on `codeAction/resolve`, the action is left out
+func (s *DefaultServer) adaptCodeActions(context CodeActionContext, actions []CodeAction) []interface{} {
+	var caps CodeActionClientCapabilities
+	if tdc := s.ClientCapabilities().TextDocumentCapabilities; tdc != nil && tdc.CodeAction != nil {
+		caps = *tdc.CodeAction
+	}
+	literals := caps.CodeActionLiteralSupport != nil
+	_, applyEdits := s.commands[applyCodeActionEditCommand]
+	result := []interface{}{}
+	for i := range actions {
+		action := actions[i]
+		if action.Kind != nil && !context.Requests(*action.Kind) {
+			continue
+		}
+		if !literals {
+			if action.Disabled != nil {
+				continue
+			}
+			if action.Edit != nil && applyEdits {
+				arguments := []interface{}{action.Title, action.Edit}
+				if action.Command != nil {
+					arguments = append(arguments, action.Command)
+				}
+				result = append(result, Command{Title: action.Title, Command: applyCodeActionEditCommand, Arguments: arguments})
+			} else if action.Edit == nil && action.Command != nil {
+				result = append(result, *action.Command)
+			}
+			continue
+		}
+		if action.Disabled != nil && (caps.DisabledSupport == nil || !*caps.DisabledSupport) {
+			continue
+		}
+		if caps.IsPreferredSupport == nil || !*caps.IsPreferredSupport {
+			action.IsPreferred = nil
+		}
+		result = append(result, action)
+	}
+	return result
+}
+
+func (s *DefaultServer) resolveCodeAction(req *jsonrpc2.Request) {
+	resolver, ok := s.provider().(CodeActionResolver)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	action := CodeAction{}
+	if err := decodeParams(req, &action); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	resolved, err := resolver.ResolveCodeAction(&action)
+	s.reply(req, resolved, err)
+}
@@ -0,0 +1,163 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+type codeActionServer struct {
+	*testServer
+	actions []CodeAction
+}
+
+func (s *codeActionServer) CodeActionKinds() []CodeActionKind {
+	return []CodeActionKind{CodeActionKindQuickFix}
+}
+
+func (s *codeActionServer) CodeActions(params *CodeActionParams) ([]CodeAction, error) {
+	return s.actions, nil
+}
+
+func testCodeActions() []CodeAction {
+	edit := WorkspaceEdit{Changes: map[code.DocumentURI][]TextEdit{"file:///a": {{Range: rng(0, 0, 0, 1), NewText: "x"}}}}
+	fix := NewQuickFix("Fix it", &edit)
+	fix.Prefer()
+	withCommand := NewQuickFix("Fix and run", &edit)
+	withCommand.Command = &Command{Title: "Run", Command: "dsl.run", Arguments: []interface{}{"arg"}}
+	disabled := NewQuickFix("Disabled", &edit)
+	disabled.Disable("not now")
+	return []CodeAction{
+		fix,
+		withCommand,
+		{Title: "Command only", Command: &Command{Title: "Organize", Command: "dsl.organize"}},
+		disabled,
+		{Title: "Lazy", Data: &json.RawMessage{'1'}},
+	}
+}
+
+func TestAdaptCodeActions(t *testing.T) {
+	yes := true
+	tests := []struct {
+		name         string
+		capabilities ClientCapabilities
+		titles       []string
+		commands     []string
+	}{
+		{
+			name: "literals",
+			capabilities: ClientCapabilities{TextDocumentCapabilities: &TextDocumentClientCapabilities{CodeAction: &CodeActionClientCapabilities{
+				CodeActionLiteralSupport: &codeActionLiteralSupport{},
+			}}},
+			titles: []string{"Fix it", "Fix and run", "Command only", "Lazy"},
+		},
+		{
+			name: "literals with disabled support",
+			capabilities: ClientCapabilities{TextDocumentCapabilities: &TextDocumentClientCapabilities{CodeAction: &CodeActionClientCapabilities{
+				CodeActionLiteralSupport: &codeActionLiteralSupport{},
+				DisabledSupport:          &yes,
+			}}},
+			titles: []string{"Fix it", "Fix and run", "Command only", "Disabled", "Lazy"},
+		},
+		{
+			name:         "commands with applyEdit",
+			capabilities: ClientCapabilities{WorkspaceCapabilities: &WorkspaceCapabilities{ApplyEdit: &yes}},
+			titles:       []string{"Fix it", "Fix and run", "Organize"},
+			commands:     []string{applyCodeActionEditCommand, applyCodeActionEditCommand, "dsl.organize"},
+		},
+		{
+			name:     "commands only",
+			titles:   []string{"Organize"},
+			commands: []string{"dsl.organize"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &codeActionServer{testServer: newTestServer()}
+			s.Init(s)
+			s.clientCapabilities = tt.capabilities
+			s.registerCodeActionEditCommand()
+			titles, commands := []string{}, []string{}
+			for _, a := range s.adaptCodeActions(CodeActionContext{}, testCodeActions()) {
+				switch a := a.(type) {
+				case CodeAction:
+					titles = append(titles, a.Title)
+					if a.IsPreferred != nil {
+						t.Errorf("isPreferred sent to a client without support for it")
+					}
+				case Command:
+					titles = append(titles, a.Title)
+					commands = append(commands, a.Command)
+				}
+			}
+			if !reflect.DeepEqual(titles, tt.titles) {
+				t.Errorf("got actions %q, want %q", titles, tt.titles)
+			}
+			if len(tt.commands) > 0 && !reflect.DeepEqual(commands, tt.commands) {
+				t.Errorf("got commands %q, want %q", commands, tt.commands)
+			}
+		})
+	}
+}
+
+func TestAdaptCodeActionsFiltersKinds(t *testing.T) {
+	s := &codeActionServer{testServer: newTestServer()}
+	s.Init(s)
+	s.clientCapabilities.TextDocumentCapabilities = &TextDocumentClientCapabilities{CodeAction: &CodeActionClientCapabilities{
+		CodeActionLiteralSupport: &codeActionLiteralSupport{},
+	}}
+	refactor := CodeActionKindRefactorExtract
+	actions := append(testCodeActions()[:1], CodeAction{Title: "Extract", Kind: &refactor})
+	got := s.adaptCodeActions(CodeActionContext{Only: []CodeActionKind{CodeActionKindRefactor}}, actions)
+	if len(got) != 1 || got[0].(CodeAction).Title != "Extract" {
+		t.Errorf("got %v, want only the refactoring", got)
+	}
+}
+
+func TestCodeActionEditFallback(t *testing.T) {
+	s := &codeActionServer{testServer: newTestServer(), actions: testCodeActions()[1:2]}
+	ran := make(chan string, 1)
+	if err := s.RegisterCommand("dsl.run", func(ctx interface{}, arg string) error { return nil }); err == nil {
+		t.Fatal("a handler without a context must be rejected")
+	}
+	s.RegisterCommand("dsl.run", func(ctx context.Context, arg string) error {
+		ran <- arg
+		return nil
+	})
+	c := startTestServer(t, s.DefaultServer, s)
+	capabilities := c.initialize(`{"capabilities":{"workspace":{"applyEdit":true}}}`)
+	commands := ExecuteCommandOptions{}
+	decode(t, capabilities["executeCommandProvider"], &commands)
+	if !reflect.DeepEqual(commands.Commands, []string{"dsl.run", applyCodeActionEditCommand}) {
+		t.Fatalf("got commands %q", commands.Commands)
+	}
+
+	c.request(1, "textDocument/codeAction", CodeActionParams{TextDocument: TextDocumentIdentifier{URI: "file:///a"}})
+	response := c.next()
+	var offered []Command
+	decode(t, response.Result, &offered)
+	if len(offered) != 1 || offered[0].Command != applyCodeActionEditCommand {
+		t.Fatalf("got %s, want the edit wrapped in a command", response.Result)
+	}
+
+	c.request(2, "workspace/executeCommand", offered[0])
+	applyEdit := c.next()
+	if applyEdit.Method != "workspace/applyEdit" {
+		t.Fatalf("got %+v, want a workspace/applyEdit request", applyEdit)
+	}
+	params := ApplyWorkspaceEditParams{}
+	decode(t, applyEdit.Params, &params)
+	if params.Label == nil || *params.Label != "Fix and run" || len(params.Edit.Changes["file:///a"]) != 1 {
+		t.Errorf("got applyEdit params %s", applyEdit.Params)
+	}
+	c.respond(applyEdit.ID, ApplyWorkspaceEditResponse{Applied: true})
+	if response := c.next(); response.ID == nil || response.ID.NumberID != 2 || response.Error != nil {
+		t.Fatalf("got %+v, want a successful response to the command", response)
+	}
+	if arg := <-ran; arg != "arg" {
+		t.Errorf("the action's command ran with %q", arg)
+	}
+}
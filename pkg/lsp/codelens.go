@@ -0,0 +1,153 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//CodeLensParams are the parameters of a `textDocument/codeLens` request
+type CodeLensParams struct {
+	//The document to request code lens for
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+//CodeLens represents a command that should be shown along with source text, like the number of references, a way to run tests, etc.
+//A code lens is _unresolved_ when no command is associated to it. For performance reasons the creation of a code lens and resolving should be done in two stages
+type CodeLens struct {
+	//Range in which this code lens is valid. Should only span a single line
+	Range code.Range `json:"range"`
+	//Command this code lens represents
+	Command *Command `json:"command,omitempty"`
+	//Data is preserved between a `textDocument/codeLens` and a `codeLens/resolve` request
+	Data *json.RawMessage `json:"data,omitempty"`
+}
+
+//CodeLensOptions are the server capabilities for code lenses
+type CodeLensOptions struct {
+	*WorkDoneProgressOptions
+	//ResolveProvider indicates that code lens has a resolve provider as well
+	ResolveProvider *bool `json:"resolveProvider,omitempty"`
+}
+
+//CodeLensProvider is implemented by embedding servers that compute code lenses for `textDocument/codeLens`
+type CodeLensProvider interface {
+	CodeLenses(params *CodeLensParams) ([]CodeLens, error)
+}
+
+//CodeLensResolver is optionally implemented by a `CodeLensProvider` to compute the command of a code lens lazily on `codeLens/resolve`
+type CodeLensResolver interface {
+	ResolveCodeLens(lens *CodeLens) (*CodeLens, error)
+}
+
+func (s *DefaultServer) codeLens(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(CodeLensProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := CodeLensParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	lenses, err := provider.CodeLenses(&params)
+	s.reply(req, lenses, err)
+}
+
+func (s *DefaultServer) resolveCodeLens(req *jsonrpc2.Request) {
+	resolver, ok := s.provider().(CodeLensResolver)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	lens := CodeLens{}
+	if err := decodeParams(req, &lens); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	resolved, err := resolver.ResolveCodeLens(&lens)
+	s.reply(req, resolved, err)
+}
+
+//RefreshCodeLenses sends `workspace/codeLens/refresh` to ask the client to re-request all code lenses, if the client supports it.
+//Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) RefreshCodeLenses(ctx context.Context) error {
+	var supported *bool
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc != nil && wc.CodeLens != nil {
+		supported = wc.CodeLens.RefreshSupport
+	}
+	return s.refresh(ctx, "workspace/codeLens/refresh", supported)
+}
+
+//CodeLensCache holds the code lenses computed for each document version, and asks the client to refresh its code lenses
+//when a document changes to a version the cached lenses were not computed for. It is safe for concurrent use.
+//A cache created with a server follows `textDocument/didChange` and `textDocument/didClose` by itself;
+//a cache without a server must be told of changes with `DocumentChanged` and `DocumentClosed`
+type CodeLensCache struct {
+	server *DefaultServer
+	mutex  sync.Mutex
+	lenses map[code.DocumentURI]versionedCodeLenses
+}
+
+type versionedCodeLenses struct {
+	version int64
+	lenses  []CodeLens
+}
+
+//NewCodeLensCache creates a code lens cache that sends refresh requests through `server`, and is kept in sync with the documents it receives
+func NewCodeLensCache(server *DefaultServer) *CodeLensCache {
+	c := &CodeLensCache{
+		server: server,
+		lenses: make(map[code.DocumentURI]versionedCodeLenses),
+	}
+	if server != nil {
+		server.addDocumentListener(c)
+	}
+	return c
+}
+
+//Get returns the code lenses of version `version` of the document, calling `compute` to produce and cache them if needed
+func (c *CodeLensCache) Get(uri code.DocumentURI, version int64, compute func() ([]CodeLens, error)) ([]CodeLens, error) {
+	c.mutex.Lock()
+	cached, ok := c.lenses[uri]
+	c.mutex.Unlock()
+	if ok && cached.version == version {
+		return cached.lenses, nil
+	}
+	lenses, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if current, ok := c.lenses[uri]; !ok || current.version <= version {
+		c.lenses[uri] = versionedCodeLenses{version: version, lenses: lenses}
+	}
+	return lenses, nil
+}
+
+//DocumentChanged invalidates the cached code lenses of the document if they were computed for another version,
+//and asks the client to refresh its code lenses in the background
+func (c *CodeLensCache) DocumentChanged(uri code.DocumentURI, version int64) {
+	c.mutex.Lock()
+	cached, ok := c.lenses[uri]
+	stale := ok && cached.version != version
+	if stale {
+		delete(c.lenses, uri)
+	}
+	c.mutex.Unlock()
+	if stale && c.server != nil {
+		go c.server.RefreshCodeLenses(context.Background())
+	}
+}
+
+//DocumentClosed drops the cached code lenses of the document
+func (c *CodeLensCache) DocumentClosed(uri code.DocumentURI) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.lenses, uri)
+}
@@ -0,0 +1,52 @@
+package lsp
+
+import "testing"
+
+func TestCodeLensCacheFollowsDocuments(t *testing.T) {
+	s := newTestServer()
+	cache := NewCodeLensCache(s.DefaultServer)
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{"workspace":{"codeLens":{"refreshSupport":true}}}}`)
+
+	computed := 0
+	get := func(version int64) {
+		t.Helper()
+		lenses, err := cache.Get("file:///a.dsl", version, func() ([]CodeLens, error) {
+			computed++
+			return []CodeLens{{Range: rng(0, 0, 0, 1)}}, nil
+		})
+		if err != nil || len(lenses) != 1 {
+			t.Fatalf("got %v, %v", lenses, err)
+		}
+	}
+	get(1)
+	get(1)
+	if computed != 1 {
+		t.Errorf("computed the lenses of the same version %d times", computed)
+	}
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": "file:///a.dsl", "version": 2},
+		"contentChanges": []interface{}{},
+	})
+	s.expectForwarded(t, "textDocument/didChange")
+	refresh := c.next()
+	if refresh.Method != "workspace/codeLens/refresh" {
+		t.Fatalf("got %+v, want a refresh request", refresh)
+	}
+	c.respond(refresh.ID, nil)
+	get(2)
+	if computed != 2 {
+		t.Errorf("computed %d times, want the lenses of the new version computed", computed)
+	}
+
+	c.notify("textDocument/didClose", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": "file:///a.dsl"},
+	})
+	s.expectForwarded(t, "textDocument/didClose")
+	c.expectNone()
+	get(2)
+	if computed != 3 {
+		t.Errorf("computed %d times, want the lenses computed again after the document was closed", computed)
+	}
+}
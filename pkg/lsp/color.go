@@ -0,0 +1,323 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//Color represents a color in RGBA space, with each component in the range [0, 1]
+type Color struct {
+	Red   float64 `json:"red"`
+	Green float64 `json:"green"`
+	Blue  float64 `json:"blue"`
+	Alpha float64 `json:"alpha"`
+}
+
+//ColorInformation is a color found in a document
+type ColorInformation struct {
+	//Range in the document where this color appears
+	Range code.Range `json:"range"`
+	//Color is the actual color value for this color range
+	Color Color `json:"color"`
+}
+
+//DocumentColorParams are the parameters of a `textDocument/documentColor` request
+type DocumentColorParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+//ColorPresentationParams are the parameters of a `textDocument/colorPresentation` request
+type ColorPresentationParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//Color to request presentations for
+	Color Color `json:"color"`
+	//Range where the color would be inserted. Serves as a context
+	Range code.Range `json:"range"`
+}
+
+//ColorPresentation is one way of writing a color, as offered by the editor's color picker
+type ColorPresentation struct {
+	//Label of this color presentation. It will be shown on the color picker header. By default this is also the text that is inserted when selecting this color presentation
+	Label string `json:"label"`
+	//TextEdit which is applied to a document when selecting this presentation for the color. When omitted the `label` is used
+	TextEdit *TextEdit `json:"textEdit,omitempty"`
+	//AdditionalTextEdits which are applied when selecting this color presentation. Edits must not overlap with the main `textEdit` nor with themselves
+	AdditionalTextEdits []TextEdit `json:"additionalTextEdits,omitempty"`
+}
+
+//DocumentColorOptions are the server capabilities for document colors
+type DocumentColorOptions struct {
+	*WorkDoneProgressOptions
+}
+
+//DocumentColorRegistrationOptions are the registration options for document colors
+type DocumentColorRegistrationOptions struct {
+	*TextDocumentRegistrationOptions
+	*StaticRegistrationOptions
+	*DocumentColorOptions
+}
+
+type colorUnion struct {
+	Boolean             *bool
+	Options             *DocumentColorOptions
+	RegistrationOptions *DocumentColorRegistrationOptions
+}
+
+func (cu *colorUnion) MarshalJSON() ([]byte, error) {
+	if cu.Boolean != nil {
+		return json.Marshal(*cu.Boolean)
+	}
+	if cu.Options != nil {
+		return json.Marshal(*cu.Options)
+	}
+	return json.Marshal(cu.RegistrationOptions)
+}
+
+func (cu *colorUnion) UnmarshalJSON(js []byte) error {
+	*cu = colorUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		cu.Boolean = &b
+		return nil
+	}
+	cu.RegistrationOptions = &DocumentColorRegistrationOptions{}
+	return json.Unmarshal(js, cu.RegistrationOptions)
+}
+
+//DocumentColorProvider is implemented by embedding servers that find colors for `textDocument/documentColor`
+//and present them for `textDocument/colorPresentation`. `DefaultColorPresentations` offers the usual hex, rgb() and hsl() forms
+type DocumentColorProvider interface {
+	DocumentColors(params *DocumentColorParams) ([]ColorInformation, error)
+	ColorPresentations(params *ColorPresentationParams) ([]ColorPresentation, error)
+}
+
+func (s *DefaultServer) documentColor(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DocumentColorProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := DocumentColorParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	colors, err := provider.DocumentColors(&params)
+	s.reply(req, colors, err)
+}
+
+func (s *DefaultServer) colorPresentation(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DocumentColorProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := ColorPresentationParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	presentations, err := provider.ColorPresentations(&params)
+	s.reply(req, presentations, err)
+}
+
+//DefaultColorPresentations presents `color` in hex, rgb() and hsl() notation, each replacing the text at `r`
+func DefaultColorPresentations(color Color, r code.Range) []ColorPresentation {
+	presentations := []ColorPresentation{}
+	for _, label := range []string{color.Hex(), color.RGB(), color.HSL()} {
+		presentations = append(presentations, ColorPresentation{
+			Label:    label,
+			TextEdit: &TextEdit{Range: r, NewText: label},
+		})
+	}
+	return presentations
+}
+
+//ParseColor parses a CSS-style color: `#rgb`, `#rgba`, `#rrggbb`, `#rrggbbaa`, `rgb()`/`rgba()` and `hsl()`/`hsla()`.
+//Functional notations accept comma or space separated arguments, with an optional `/` before the alpha component
+func ParseColor(s string) (Color, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "#") {
+		return parseHexColor(s)
+	}
+	open, end := strings.Index(s, "("), len(s)-1
+	if open < 0 || end < open || s[end] != ')' {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	name := strings.ToLower(strings.TrimSpace(s[:open]))
+	args := strings.Fields(strings.NewReplacer(",", " ", "/", " ").Replace(s[open+1 : end]))
+	if len(args) != 3 && len(args) != 4 {
+		return Color{}, fmt.Errorf("invalid color %q: expected 3 or 4 components", s)
+	}
+	alpha := 1.0
+	if len(args) == 4 {
+		a, err := parseComponent(args[3], 1)
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid alpha in color %q: %v", s, err)
+		}
+		alpha = a
+	}
+	switch name {
+	case "rgb", "rgba":
+		var rgb [3]float64
+		for i := range rgb {
+			c, err := parseComponent(args[i], 255)
+			if err != nil {
+				return Color{}, fmt.Errorf("invalid color %q: %v", s, err)
+			}
+			rgb[i] = c
+		}
+		return Color{Red: rgb[0], Green: rgb[1], Blue: rgb[2], Alpha: alpha}, nil
+	case "hsl", "hsla":
+		hue, err := parseNumber(strings.TrimSuffix(strings.ToLower(args[0]), "deg"))
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid hue in color %q: %v", s, err)
+		}
+		saturation, err := parseComponent(strings.TrimSuffix(args[1], "%")+"%", 100)
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid saturation in color %q: %v", s, err)
+		}
+		lightness, err := parseComponent(strings.TrimSuffix(args[2], "%")+"%", 100)
+		if err != nil {
+			return Color{}, fmt.Errorf("invalid lightness in color %q: %v", s, err)
+		}
+		return hslToColor(hue, saturation, lightness, alpha), nil
+	}
+	return Color{}, fmt.Errorf("unknown color function %q", name)
+}
+
+func parseHexColor(s string) (Color, error) {
+	digits := s[1:]
+	if len(digits) == 3 || len(digits) == 4 {
+		expanded := make([]byte, 0, 2*len(digits))
+		for i := 0; i < len(digits); i++ {
+			expanded = append(expanded, digits[i], digits[i])
+		}
+		digits = string(expanded)
+	}
+	if len(digits) != 6 && len(digits) != 8 {
+		return Color{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	if len(digits) == 6 {
+		value = value<<8 | 0xff
+	}
+	return Color{
+		Red:   float64(value>>24&0xff) / 255,
+		Green: float64(value>>16&0xff) / 255,
+		Blue:  float64(value>>8&0xff) / 255,
+		Alpha: float64(value&0xff) / 255,
+	}, nil
+}
+
+//parseComponent parses a number in [0, max] or a percentage, returning it scaled to [0, 1]
+func parseComponent(s string, max float64) (float64, error) {
+	scale := max
+	if strings.HasSuffix(s, "%") {
+		s, scale = strings.TrimSuffix(s, "%"), 100
+	}
+	v, err := parseNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	return math.Min(1, math.Max(0, v/scale)), nil
+}
+
+//parseNumber parses a finite number. `strconv.ParseFloat` also accepts "nan" and "inf", which no color component can hold
+func parseNumber(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("%q is not a finite number", s)
+	}
+	return v, nil
+}
+
+//Hex formats the color as `#rrggbb`, or `#rrggbbaa` if it is not opaque
+func (c Color) Hex() string {
+	hex := fmt.Sprintf("#%02x%02x%02x", to8Bit(c.Red), to8Bit(c.Green), to8Bit(c.Blue))
+	if c.Alpha < 1 {
+		hex += fmt.Sprintf("%02x", to8Bit(c.Alpha))
+	}
+	return hex
+}
+
+//RGB formats the color as `rgb(r, g, b)`, or `rgba(r, g, b, a)` if it is not opaque
+func (c Color) RGB() string {
+	if c.Alpha < 1 {
+		return fmt.Sprintf("rgba(%d, %d, %d, %s)", to8Bit(c.Red), to8Bit(c.Green), to8Bit(c.Blue), formatAlpha(c.Alpha))
+	}
+	return fmt.Sprintf("rgb(%d, %d, %d)", to8Bit(c.Red), to8Bit(c.Green), to8Bit(c.Blue))
+}
+
+//HSL formats the color as `hsl(h, s%, l%)`, or `hsla(h, s%, l%, a)` if it is not opaque
+func (c Color) HSL() string {
+	max := math.Max(c.Red, math.Max(c.Green, c.Blue))
+	min := math.Min(c.Red, math.Min(c.Green, c.Blue))
+	lightness := (max + min) / 2
+	var hue, saturation float64
+	if delta := max - min; delta > 0 {
+		saturation = delta / (1 - math.Abs(2*lightness-1))
+		switch max {
+		case c.Red:
+			hue = math.Mod((c.Green-c.Blue)/delta+6, 6)
+		case c.Green:
+			hue = (c.Blue-c.Red)/delta + 2
+		default:
+			hue = (c.Red-c.Green)/delta + 4
+		}
+		hue *= 60
+	}
+	h, s, l := math.Round(hue), math.Round(saturation*100), math.Round(lightness*100)
+	if h == 360 {
+		h = 0
+	}
+	if c.Alpha < 1 {
+		return fmt.Sprintf("hsla(%g, %g%%, %g%%, %s)", h, s, l, formatAlpha(c.Alpha))
+	}
+	return fmt.Sprintf("hsl(%g, %g%%, %g%%)", h, s, l)
+}
+
+func hslToColor(hue, saturation, lightness, alpha float64) Color {
+	hue = math.Mod(math.Mod(hue, 360)+360, 360) / 60
+	chroma := (1 - math.Abs(2*lightness-1)) * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue, 2)-1))
+	var r, g, b float64
+	switch {
+	case hue < 1:
+		r, g = chroma, x
+	case hue < 2:
+		r, g = x, chroma
+	case hue < 3:
+		g, b = chroma, x
+	case hue < 4:
+		g, b = x, chroma
+	case hue < 5:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	m := lightness - chroma/2
+	return Color{Red: r + m, Green: g + m, Blue: b + m, Alpha: alpha}
+}
+
+func to8Bit(component float64) int {
+	return int(math.Round(math.Min(1, math.Max(0, component)) * 255))
+}
+
+func formatAlpha(alpha float64) string {
+	return strconv.FormatFloat(math.Round(alpha*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,105 @@
+package lsp
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func closeColors(a, b Color, tolerance float64) bool {
+	return math.Abs(a.Red-b.Red) <= tolerance && math.Abs(a.Green-b.Green) <= tolerance &&
+		math.Abs(a.Blue-b.Blue) <= tolerance && math.Abs(a.Alpha-b.Alpha) <= tolerance
+}
+
+func TestParseColor(t *testing.T) {
+	valid := []struct {
+		input string
+		want  Color
+	}{
+		{"#f00", Color{Red: 1, Alpha: 1}},
+		{"#0f08", Color{Green: 1, Alpha: 136.0 / 255}},
+		{"#00ff00", Color{Green: 1, Alpha: 1}},
+		{"#0000FF80", Color{Blue: 1, Alpha: 128.0 / 255}},
+		{" RGB(255,0,0) ", Color{Red: 1, Alpha: 1}},
+		{"rgba(0 128 255 / 50%)", Color{Green: 128.0 / 255, Blue: 1, Alpha: 0.5}},
+		{"rgb(300, -5, 50%)", Color{Red: 1, Blue: 0.5, Alpha: 1}},
+		{"hsl(120deg, 100%, 50%)", Color{Green: 1, Alpha: 1}},
+		{"hsla(-120, 100, 50, 0.25)", Color{Blue: 1, Alpha: 0.25}},
+		{"hsl(0 0% 100%)", Color{Red: 1, Green: 1, Blue: 1, Alpha: 1}},
+	}
+	for _, test := range valid {
+		got, err := ParseColor(test.input)
+		if err != nil {
+			t.Errorf("ParseColor(%q): %v", test.input, err)
+		} else if !closeColors(got, test.want, 1e-9) {
+			t.Errorf("ParseColor(%q) = %+v, want %+v", test.input, got, test.want)
+		}
+	}
+
+	invalid := []string{
+		"", "red", "#", "#ff", "#fffff", "#gggggg", "rgb(1, 2)", "rgb(1, 2, 3, 4, 5)", "rgb(1, 2, 3",
+		"cmyk(1, 2, 3)", "rgb(a, 0, 0)", "hsl(red, 50%, 50%)", "rgba(0, 0, 0, half)",
+		//non-finite values
+		"rgb(nan, 0, 0)", "rgb(0, inf, 0)", "rgb(0, 0, -Inf%)", "rgba(0, 0, 0, NaN)",
+		"hsl(infinity, 50%, 50%)", "hsl(0, nan%, 50%)", "hsl(0, 50%, +inf)",
+	}
+	for _, input := range invalid {
+		if got, err := ParseColor(input); err == nil {
+			t.Errorf("ParseColor(%q) = %+v, want an error", input, got)
+		}
+	}
+}
+
+func TestColorFormats(t *testing.T) {
+	tests := []struct {
+		color         Color
+		hex, rgb, hsl string
+	}{
+		{Color{Red: 1, Alpha: 1}, "#ff0000", "rgb(255, 0, 0)", "hsl(0, 100%, 50%)"},
+		{Color{Green: 0.5, Blue: 1, Alpha: 0.5}, "#0080ff80", "rgba(0, 128, 255, 0.5)", "hsla(210, 100%, 50%, 0.5)"},
+		{Color{Red: 0.2, Green: 0.2, Blue: 0.2, Alpha: 1}, "#333333", "rgb(51, 51, 51)", "hsl(0, 0%, 20%)"},
+		{Color{Red: 1, Blue: 1, Alpha: 0}, "#ff00ff00", "rgba(255, 0, 255, 0)", "hsla(300, 100%, 50%, 0)"},
+	}
+	for _, test := range tests {
+		if got := test.color.Hex(); got != test.hex {
+			t.Errorf("%+v.Hex() = %q, want %q", test.color, got, test.hex)
+		}
+		if got := test.color.RGB(); got != test.rgb {
+			t.Errorf("%+v.RGB() = %q, want %q", test.color, got, test.rgb)
+		}
+		if got := test.color.HSL(); got != test.hsl {
+			t.Errorf("%+v.HSL() = %q, want %q", test.color, got, test.hsl)
+		}
+	}
+}
+
+func TestColorRoundTrips(t *testing.T) {
+	colors := []Color{
+		{Red: 1, Alpha: 1},
+		{Red: 0.1, Green: 0.6, Blue: 0.3, Alpha: 1},
+		{Red: 0.9, Green: 0.85, Blue: 0.05, Alpha: 0.4},
+		{Red: 0.5, Green: 0.5, Blue: 0.5, Alpha: 1},
+		{Alpha: 0},
+	}
+	formats := map[string]func(Color) string{"Hex": Color.Hex, "RGB": Color.RGB, "HSL": Color.HSL}
+	for _, color := range colors {
+		for name, format := range formats {
+			text := format(color)
+			parsed, err := ParseColor(text)
+			if err != nil {
+				t.Errorf("cannot parse %s %q of %+v: %v", name, text, color, err)
+				continue
+			}
+			//hex and rgb() keep 8 bits per component, hsl() whole percentages
+			if !closeColors(parsed, color, 0.01) {
+				t.Errorf("%s %q of %+v parsed as %+v", name, text, color, parsed)
+			}
+			if again := format(parsed); again != text {
+				t.Errorf("%s of %+v is %q, but %q after a round trip", name, color, text, again)
+			}
+			if _, err := json.Marshal(ColorInformation{Color: parsed}); err != nil {
+				t.Errorf("cannot encode %+v: %v", parsed, err)
+			}
+		}
+	}
+}
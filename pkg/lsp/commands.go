@@ -0,0 +1,224 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//ExecuteCommandOptions are the server capabilities for `workspace/executeCommand`
+type ExecuteCommandOptions struct {
+	*WorkDoneProgressOptions
+	//Commands to be executed on the server
+	Commands []string `json:"commands"`
+}
+
+//ExecuteCommandParams are the parameters of a `workspace/executeCommand` request
+type ExecuteCommandParams struct {
+	//Command is the identifier of the actual command handler
+	Command string `json:"command"`
+	//Arguments that the command should be invoked with
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+//RegisterCommand registers `handler` to execute `command` on `workspace/executeCommand` requests.
+//The handler must be a function whose first parameter is a context.Context, followed by one parameter per command argument,
+//each decoded from JSON into the parameter's type. Trailing pointer parameters are optional and are nil if the argument is absent.
+//The handler returns either an error, or a result and an error.
+//
+//Commands must be registered before the server is initialized so they can be advertised in `ExecuteCommandOptions`.
+//Handlers run on their own goroutine, so they may call back into the client, e.g. with `ApplyEdit`.
+//Their context is cancelled when the client cancels the request
+func (s *DefaultServer) RegisterCommand(command string, handler interface{}) error {
+	fn := reflect.ValueOf(handler)
+	t := fn.Type()
+	if t.Kind() != reflect.Func {
+		return fmt.Errorf("handler of command %s is a %s, not a function", command, t.Kind())
+	}
+	if t.NumIn() == 0 || t.In(0) != contextType || t.IsVariadic() {
+		return fmt.Errorf("handler of command %s must take a context.Context followed by its arguments", command)
+	}
+	if t.NumOut() == 0 || t.NumOut() > 2 || t.Out(t.NumOut()-1) != errorType {
+		return fmt.Errorf("handler of command %s must return an error, or a result and an error", command)
+	}
+	if _, exists := s.commands[command]; exists {
+		return fmt.Errorf("command %s is already registered", command)
+	}
+	if s.commands == nil {
+		s.commands = make(map[string]reflect.Value)
+	}
+	s.commands[command] = fn
+	return nil
+}
+
+func (s *DefaultServer) commandNames() []string {
+	names := make([]string, 0, len(s.commands))
+	for name := range s.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//CancelParams are the parameters of a `$/cancelRequest` notification
+type CancelParams struct {
+	//ID of the request to cancel
+	ID jsonrpc2.ID `json:"id"`
+}
+
+//codeRequestCancelled is the LSP error code of a response to a request that was cancelled by the client
+const codeRequestCancelled = -32800
+
+//executeCommand dispatches a `workspace/executeCommand` request to its registered handler.
+//Registered handlers run on their own goroutine with a context that is cancelled by `$/cancelRequest`, or when the connection closes.
+//Commands without a registered handler are forwarded to the embedding server on the `Start` loop, like any other request
+func (s *DefaultServer) executeCommand(req *jsonrpc2.Request) {
+	params := ExecuteCommandParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	fn, ok := s.commands[params.Command]
+	if !ok {
+		s.forward(req)
+		return
+	}
+	args, err := decodeCommandArguments(params, fn.Type())
+	if err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.commandMutex.Lock()
+	if s.runningCommands == nil {
+		s.runningCommands = make(map[string]context.CancelFunc)
+	}
+	s.runningCommands[req.ID.String()] = cancel
+	s.commandMutex.Unlock()
+	go func() {
+		defer func() {
+			s.commandMutex.Lock()
+			delete(s.runningCommands, req.ID.String())
+			s.commandMutex.Unlock()
+			cancel()
+		}()
+		result, handlerErr := invokeCommand(ctx, params.Command, fn, args)
+		if handlerErr != nil && ctx.Err() != nil {
+			handlerErr = &jsonrpc2.Error{
+				Code:    codeRequestCancelled,
+				Message: fmt.Sprintf("command %s was cancelled", params.Command),
+			}
+		}
+		s.reply(req, result, handlerErr)
+	}()
+}
+
+//cancelRequest cancels the context of a running command on `$/cancelRequest`.
+//The notification is forwarded to the embedding server as well, since the request may be one it is handling
+func (s *DefaultServer) cancelRequest(req *jsonrpc2.Request) {
+	params := CancelParams{}
+	if err := decodeParams(req, &params); err == nil {
+		s.commandMutex.Lock()
+		cancel, ok := s.runningCommands[params.ID.String()]
+		s.commandMutex.Unlock()
+		if ok {
+			cancel()
+		}
+	}
+	s.forward(req)
+}
+
+//cancelCommands cancels all running commands once the connection to the client is closed
+func (s *DefaultServer) cancelCommands() {
+	s.commandMutex.Lock()
+	defer s.commandMutex.Unlock()
+	for _, cancel := range s.runningCommands {
+		cancel()
+	}
+}
+
+//invokeCommand calls the handler `fn` of `command` with its decoded arguments. A panicking handler is reported as an internal error
+func invokeCommand(ctx context.Context, command string, fn reflect.Value, args []reflect.Value) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = &jsonrpc2.Error{
+				Code:    jsonrpc2.CodeInternalError,
+				Message: fmt.Sprintf("command %s panicked: %v", command, r),
+			}
+		}
+	}()
+	out := fn.Call(append([]reflect.Value{reflect.ValueOf(ctx)}, args...))
+	if len(out) == 2 {
+		result = out[0].Interface()
+	}
+	err, _ = out[len(out)-1].Interface().(error)
+	return result, err
+}
+
+//runCommand runs a command registered on the server, such as the command of a code action
+func (s *DefaultServer) runCommand(ctx context.Context, command Command) (interface{}, error) {
+	fn, ok := s.commands[command.Command]
+	if !ok {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf("command %s is not registered", command.Command),
+		}
+	}
+	params := ExecuteCommandParams{Command: command.Command}
+	for _, argument := range command.Arguments {
+		raw, err := json.Marshal(argument)
+		if err != nil {
+			return nil, err
+		}
+		params.Arguments = append(params.Arguments, raw)
+	}
+	args, err := decodeCommandArguments(params, fn.Type())
+	if err != nil {
+		return nil, err
+	}
+	return invokeCommand(ctx, command.Command, fn, args)
+}
+
+//decodeCommandArguments decodes the JSON arguments of a command into the parameter types of its handler `t`
+func decodeCommandArguments(params ExecuteCommandParams, t reflect.Type) ([]reflect.Value, *jsonrpc2.Error) {
+	expected := t.NumIn() - 1
+	if len(params.Arguments) > expected {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf("command %s takes %d arguments but got %d", params.Command, expected, len(params.Arguments)),
+		}
+	}
+	args := make([]reflect.Value, expected)
+	for i := range args {
+		argType := t.In(i + 1)
+		if i >= len(params.Arguments) {
+			if argType.Kind() != reflect.Ptr {
+				return nil, &jsonrpc2.Error{
+					Code:    jsonrpc2.CodeInvalidParams,
+					Message: fmt.Sprintf("command %s is missing argument %d", params.Command, i+1),
+				}
+			}
+			args[i] = reflect.Zero(argType)
+			continue
+		}
+		arg := reflect.New(argType)
+		if err := json.Unmarshal(params.Arguments[i], arg.Interface()); err != nil {
+			return nil, &jsonrpc2.Error{
+				Code:    jsonrpc2.CodeInvalidParams,
+				Message: fmt.Sprintf("argument %d of command %s: %v", i+1, params.Command, err),
+			}
+		}
+		args[i] = arg.Elem()
+	}
+	return args, nil
+}
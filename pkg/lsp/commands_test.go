@@ -0,0 +1,114 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+func TestRegisterCommandValidatesHandlers(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler interface{}
+		err     string
+	}{
+		{name: "not a function", handler: 42, err: "not a function"},
+		{name: "no context", handler: func(string) error { return nil }, err: "context.Context"},
+		{name: "variadic", handler: func(context.Context, ...string) error { return nil }, err: "context.Context"},
+		{name: "no error", handler: func(context.Context) string { return "" }, err: "must return an error"},
+		{name: "error only", handler: func(context.Context, string) error { return nil }},
+		{name: "result and error", handler: func(context.Context, int, *string) (string, error) { return "", nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&DefaultServer{}).RegisterCommand("test", tt.handler)
+			if tt.err == "" && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+	s := DefaultServer{}
+	handler := func(context.Context) error { return nil }
+	if err := s.RegisterCommand("test", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RegisterCommand("test", handler); err == nil {
+		t.Error("registered the same command twice")
+	}
+}
+
+func TestExecuteCommand(t *testing.T) {
+	s := newTestServer()
+	started := make(chan struct{})
+	must := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(s.RegisterCommand("add", func(ctx context.Context, a, b int, c *int) (int, error) {
+		if c != nil {
+			return a + b + *c, nil
+		}
+		return a + b, nil
+	}))
+	must(s.RegisterCommand("fail", func(ctx context.Context) error { return errors.New("failed") }))
+	must(s.RegisterCommand("panic", func(ctx context.Context) error { panic("oops") }))
+	must(s.RegisterCommand("wait", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{}}`)
+
+	tests := []struct {
+		name   string
+		params string
+		result string
+		code   int64
+	}{
+		{name: "arguments", params: `{"command":"add","arguments":[1,2]}`, result: "3"},
+		{name: "optional argument", params: `{"command":"add","arguments":[1,2,3]}`, result: "6"},
+		{name: "missing argument", params: `{"command":"add","arguments":[1]}`, code: jsonrpc2.CodeInvalidParams},
+		{name: "too many arguments", params: `{"command":"add","arguments":[1,2,3,4]}`, code: jsonrpc2.CodeInvalidParams},
+		{name: "mistyped argument", params: `{"command":"add","arguments":["1",2]}`, code: jsonrpc2.CodeInvalidParams},
+		{name: "error", params: `{"command":"fail"}`, code: jsonrpc2.CodeInternalError},
+		{name: "panic", params: `{"command":"panic"}`, code: jsonrpc2.CodeInternalError},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.t = t
+			c.request(int64(i+1), "workspace/executeCommand", json.RawMessage(tt.params))
+			response := c.next()
+			if tt.code != 0 {
+				if response.Error == nil || response.Error.Code != tt.code {
+					t.Errorf("got %+v, want error code %d", response, tt.code)
+				}
+				return
+			}
+			if response.Error != nil || string(response.Result) != tt.result {
+				t.Errorf("got %+v, want result %s", response, tt.result)
+			}
+		})
+	}
+	c.t = t
+
+	c.request(100, "workspace/executeCommand", json.RawMessage(`{"command":"unregistered"}`))
+	s.expectForwarded(t, "workspace/executeCommand")
+
+	c.request(101, "workspace/executeCommand", json.RawMessage(`{"command":"wait"}`))
+	<-started
+	c.notify("$/cancelRequest", json.RawMessage(`{"id":101}`))
+	response := c.next()
+	if response.Error == nil || response.Error.Code != codeRequestCancelled {
+		t.Errorf("got %+v, want a cancelled response", response)
+	}
+	s.expectForwarded(t, "$/cancelRequest")
+}
@@ -0,0 +1,342 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//ConfigurationItem identifies a configuration section to pull from the client
+type ConfigurationItem struct {
+	//ScopeURI is the scope to get the configuration section for, such as a workspace folder
+	ScopeURI *code.DocumentURI `json:"scopeUri,omitempty"`
+	//Section is the configuration section asked for, e.g. `mydsl.format`
+	Section *string `json:"section,omitempty"`
+}
+
+//ConfigurationParams are the parameters of a `workspace/configuration` request
+type ConfigurationParams struct {
+	Items []ConfigurationItem `json:"items"`
+}
+
+//DidChangeConfigurationParams are the parameters of a `workspace/didChangeConfiguration` notification
+type DidChangeConfigurationParams struct {
+	//Settings are the changed settings. Clients supporting `workspace/configuration` usually send none, and expect servers to pull them
+	Settings *json.RawMessage `json:"settings,omitempty"`
+}
+
+//DidChangeConfigurationRegistrationOptions are the options to register for `workspace/didChangeConfiguration`
+type DidChangeConfigurationRegistrationOptions struct {
+	Section []string `json:"section,omitempty"`
+}
+
+//errConfigurationUnsupported is returned when the client did not declare support for `workspace/configuration`
+var errConfigurationUnsupported = errors.New("client does not support workspace/configuration")
+
+//Configuration pulls configuration sections from the client with `workspace/configuration`, returning one result per item.
+//Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) Configuration(ctx context.Context, items ...ConfigurationItem) ([]json.RawMessage, error) {
+	if !s.canPullConfiguration() {
+		return nil, errConfigurationUnsupported
+	}
+	results := []json.RawMessage{}
+	if err := s.Call(ctx, "workspace/configuration", ConfigurationParams{Items: items}, &results); err != nil {
+		return nil, err
+	}
+	if len(results) != len(items) {
+		return nil, fmt.Errorf("workspace/configuration returned %d results for %d items", len(results), len(items))
+	}
+	return results, nil
+}
+
+func (s *DefaultServer) canPullConfiguration() bool {
+	wc := s.ClientCapabilities().WorkspaceCapabilities
+	return wc != nil && wc.Configuration != nil && *wc.Configuration
+}
+
+//SettingsChange tells the subscribers of `Settings` which fields of the settings of a scope changed
+type SettingsChange struct {
+	//ScopeURI is the workspace folder whose settings changed, or empty for settings outside any workspace folder
+	ScopeURI code.DocumentURI
+	//Fields are the names of the top-level struct fields that changed
+	Fields []string
+}
+
+//Settings manages a configuration section of the client, decoded into a Go struct. Settings are pulled per workspace folder
+//with `workspace/configuration` and cached, and pulled again when the client sends `workspace/didChangeConfiguration`.
+//Clients that cannot be pulled from are expected to push their settings with `workspace/didChangeConfiguration` instead.
+//The notification is still passed on to the embedding server's `Default` method. It is safe for concurrent use
+type Settings struct {
+	server      *DefaultServer
+	section     string
+	typ         reflect.Type
+	defaults    []byte
+	mutex       sync.Mutex
+	values      map[code.DocumentURI]reflect.Value
+	pushed      json.RawMessage
+	subscribers []func(SettingsChange)
+}
+
+//NewSettings creates the settings of configuration `section`, decoded into the struct type `defaults` points to.
+//Fields missing from the client's settings keep their value in `defaults`
+func (s *DefaultServer) NewSettings(section string, defaults interface{}) (*Settings, error) {
+	t := reflect.TypeOf(defaults)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || reflect.ValueOf(defaults).IsNil() {
+		return nil, fmt.Errorf("defaults of settings %q must be a non-nil pointer to a struct, not %T", section, defaults)
+	}
+	js, err := json.Marshal(defaults)
+	if err != nil {
+		return nil, err
+	}
+	settings := Settings{
+		server:   s,
+		section:  section,
+		typ:      t.Elem(),
+		defaults: js,
+		values:   make(map[code.DocumentURI]reflect.Value),
+	}
+	s.settingsMutex.Lock()
+	s.settings = append(s.settings, &settings)
+	registered := s.settingsRegistered
+	s.settingsMutex.Unlock()
+	if registered {
+		//settings created after the client is initialized are registered on their own
+		s.registerSettingsSections([]string{section})
+	}
+	return &settings, nil
+}
+
+//Subscribe registers `subscriber` to be called whenever settings previously read with `Get` change
+func (st *Settings) Subscribe(subscriber func(SettingsChange)) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	st.subscribers = append(st.subscribers, subscriber)
+}
+
+//Get decodes the settings applying to `uri` into `settings`, which must point to the struct type of the defaults.
+//The settings are those of the workspace folder containing `uri`, pulled from the client unless cached.
+//Like `Call`, it must not be invoked from the `Start` loop
+func (st *Settings) Get(ctx context.Context, uri code.DocumentURI, settings interface{}) error {
+	out := reflect.ValueOf(settings)
+	if out.Kind() != reflect.Ptr || out.IsNil() || out.Elem().Type() != st.typ {
+		return fmt.Errorf("settings %q must be decoded into a *%s, not %T", st.section, st.typ, settings)
+	}
+	scope := st.scopeOf(uri)
+	st.mutex.Lock()
+	value, ok := st.values[scope]
+	st.mutex.Unlock()
+	if !ok {
+		values, err := st.pull(ctx, []code.DocumentURI{scope})
+		if err != nil {
+			return err
+		}
+		value = values[0]
+		st.mutex.Lock()
+		st.values[scope] = value
+		st.mutex.Unlock()
+	}
+	return st.copyTo(value, out)
+}
+
+//copyTo copies a cached value into `out` through JSON, so that callers cannot modify the cache
+func (st *Settings) copyTo(value, out reflect.Value) error {
+	js, err := json.Marshal(value.Interface())
+	if err != nil {
+		return err
+	}
+	fresh := reflect.New(st.typ)
+	if err := json.Unmarshal(js, fresh.Interface()); err != nil {
+		return err
+	}
+	out.Elem().Set(fresh.Elem())
+	return nil
+}
+
+//scopeOf returns the workspace folder containing `uri`, or an empty scope if there is none
+func (st *Settings) scopeOf(uri code.DocumentURI) code.DocumentURI {
+	if folder, ok := st.server.Workspace().FolderOf(uri); ok {
+		return folder.URI
+	}
+	return ""
+}
+
+//pull gets the settings of the scopes from the client, or from the settings pushed by the client if it cannot be pulled from
+func (st *Settings) pull(ctx context.Context, scopes []code.DocumentURI) ([]reflect.Value, error) {
+	raws := make([]json.RawMessage, len(scopes))
+	if st.server.canPullConfiguration() {
+		items := []ConfigurationItem{}
+		for _, scope := range scopes {
+			item := ConfigurationItem{Section: &st.section}
+			if scope != "" {
+				scopeURI := scope
+				item.ScopeURI = &scopeURI
+			}
+			items = append(items, item)
+		}
+		results, err := st.server.Configuration(ctx, items...)
+		if err != nil {
+			return nil, err
+		}
+		raws = results
+	} else {
+		st.mutex.Lock()
+		for i := range raws {
+			raws[i] = st.pushed
+		}
+		st.mutex.Unlock()
+	}
+	values := []reflect.Value{}
+	for _, raw := range raws {
+		value, err := st.decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+//decode decodes settings sent by the client on top of the defaults
+func (st *Settings) decode(raw json.RawMessage) (reflect.Value, error) {
+	value := reflect.New(st.typ)
+	if err := json.Unmarshal(st.defaults, value.Interface()); err != nil {
+		return value, err
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return value, fmt.Errorf("invalid settings %q: %v", st.section, err)
+		}
+	}
+	return value, nil
+}
+
+//push records the settings of the section from the `settings` of a `workspace/didChangeConfiguration` notification.
+//If the section is missing, at any level of its dotted name, the defaults apply again
+func (st *Settings) push(settings *json.RawMessage) {
+	if settings == nil {
+		return
+	}
+	section := *settings
+	for _, key := range strings.Split(st.section, ".") {
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(section, &fields); err != nil {
+			section = nil
+			break
+		}
+		section = fields[key]
+	}
+	st.mutex.Lock()
+	st.pushed = section
+	st.mutex.Unlock()
+}
+
+//Refresh pulls the settings of all cached scopes again and notifies the subscribers of those that changed.
+//Like `Call`, it must not be invoked from the `Start` loop
+func (st *Settings) Refresh(ctx context.Context) error {
+	scopes := []code.DocumentURI{}
+	st.mutex.Lock()
+	for scope := range st.values {
+		if _, ok := st.server.Workspace().FolderOf(scope); ok || scope == "" {
+			scopes = append(scopes, scope)
+		} else {
+			delete(st.values, scope)
+		}
+	}
+	st.mutex.Unlock()
+	if len(scopes) == 0 {
+		return nil
+	}
+	values, err := st.pull(ctx, scopes)
+	if err != nil {
+		return err
+	}
+	changes := []SettingsChange{}
+	st.mutex.Lock()
+	for i, scope := range scopes {
+		if previous, ok := st.values[scope]; ok {
+			if fields := changedFields(previous.Elem(), values[i].Elem()); len(fields) > 0 {
+				changes = append(changes, SettingsChange{ScopeURI: scope, Fields: fields})
+			}
+		}
+		st.values[scope] = values[i]
+	}
+	subscribers := append([]func(SettingsChange){}, st.subscribers...)
+	st.mutex.Unlock()
+	for _, change := range changes {
+		for _, subscriber := range subscribers {
+			subscriber(change)
+		}
+	}
+	return nil
+}
+
+//changedFields returns the names of the top-level fields that differ between two values of the same struct type
+func changedFields(previous, current reflect.Value) []string {
+	fields := []string{}
+	for i := 0; i < previous.NumField(); i++ {
+		if field := previous.Type().Field(i); field.PkgPath == "" && !reflect.DeepEqual(previous.Field(i).Interface(), current.Field(i).Interface()) {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields
+}
+
+func (s *DefaultServer) allSettings() []*Settings {
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+	return append([]*Settings{}, s.settings...)
+}
+
+func (s *DefaultServer) didChangeConfiguration(req *jsonrpc2.Request) {
+	if settings := s.allSettings(); len(settings) > 0 {
+		params := DidChangeConfigurationParams{}
+		if err := decodeParams(req, &params); err == nil {
+			for _, st := range settings {
+				st.push(params.Settings)
+			}
+			go func() {
+				for _, st := range settings {
+					if err := st.Refresh(context.Background()); err != nil {
+						s.LogMessage(MessageTypeError, fmt.Sprintf("cannot refresh settings %q: %v", st.section, err))
+					}
+				}
+			}()
+		}
+	}
+	s.forward(req)
+}
+
+//registerSettings asks clients supporting dynamic registration to send `workspace/didChangeConfiguration` for the managed settings sections.
+//Settings created afterwards are registered by `NewSettings`
+func (s *DefaultServer) registerSettings() {
+	wc := s.ClientCapabilities().WorkspaceCapabilities
+	if wc == nil || wc.DidChangeConfiguration == nil || wc.DidChangeConfiguration.DynamicRegistration == nil || !*wc.DidChangeConfiguration.DynamicRegistration {
+		return
+	}
+	sections := []string{}
+	s.settingsMutex.Lock()
+	s.settingsRegistered = true
+	for _, st := range s.settings {
+		sections = append(sections, st.section)
+	}
+	s.settingsMutex.Unlock()
+	if len(sections) > 0 {
+		s.registerSettingsSections(sections)
+	}
+}
+
+func (s *DefaultServer) registerSettingsSections(sections []string) {
+	options := DidChangeConfigurationRegistrationOptions{Section: sections}
+	go func() {
+		if _, err := s.RegisterCapability(context.Background(), "workspace/didChangeConfiguration", options); err != nil {
+			s.LogMessage(MessageTypeError, fmt.Sprintf("cannot register settings %q: %v", sections, err))
+		}
+	}()
+}
@@ -0,0 +1,127 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+type formatSettings struct {
+	TabSize int  `json:"tabSize"`
+	Tabs    bool `json:"tabs"`
+}
+
+func TestSettingsPush(t *testing.T) {
+	s := &DefaultServer{}
+	st, err := s.NewSettings("mydsl.format", &formatSettings{TabSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed := func() formatSettings {
+		t.Helper()
+		values, err := st.pull(context.Background(), []code.DocumentURI{""})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *values[0].Interface().(*formatSettings)
+	}
+	push := func(settings string) {
+		raw := json.RawMessage(settings)
+		st.push(&raw)
+	}
+	for _, missing := range []string{`{"mydsl":{}}`, `{"other":{"format":{"tabSize":8}}}`, `{"mydsl":3}`, `{}`} {
+		push(`{"mydsl":{"format":{"tabSize":8,"tabs":true}}}`)
+		if got, want := pushed(), (formatSettings{TabSize: 8, Tabs: true}); got != want {
+			t.Fatalf("got %+v, want %+v", got, want)
+		}
+		push(missing)
+		if got, want := pushed(), (formatSettings{TabSize: 4}); got != want {
+			t.Errorf("got %+v after pushing %s, want the defaults %+v", got, missing, want)
+		}
+	}
+	push(`{"mydsl":{"format":{"tabSize":2}}}`)
+	st.push(nil)
+	if got, want := pushed(), (formatSettings{TabSize: 2}); got != want {
+		t.Errorf("got %+v after a notification without settings, want %+v", got, want)
+	}
+}
+
+//expectRegisteredSections fails the test unless the next message registers `workspace/didChangeConfiguration` for `sections`, and returns its id
+func expectRegisteredSections(c *testClient, sections string) *jsonrpc2.ID {
+	c.t.Helper()
+	request := c.next()
+	params := RegistrationParams{}
+	decode(c.t, request.Params, &params)
+	if request.Method != "client/registerCapability" || len(params.Registrations) != 1 || params.Registrations[0].Method != "workspace/didChangeConfiguration" {
+		c.t.Fatalf("got %+v, want a registration of workspace/didChangeConfiguration", request)
+	}
+	if options, _ := json.Marshal(params.Registrations[0].RegisterOptions); string(options) != `{"section":`+sections+`}` {
+		c.t.Errorf("registered %s, want sections %s", options, sections)
+	}
+	return request.ID
+}
+
+func TestSettingsRegistration(t *testing.T) {
+	s := newTestServer()
+	if _, err := s.NewSettings("mydsl", &formatSettings{}); err != nil {
+		t.Fatal(err)
+	}
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{"workspace":{"didChangeConfiguration":{"dynamicRegistration":true}}}}`)
+	c.respond(expectRegisteredSections(c, `["mydsl"]`), nil)
+
+	if _, err := s.NewSettings("other", &formatSettings{}); err != nil {
+		t.Fatal(err)
+	}
+	id := expectRegisteredSections(c, `["other"]`)
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": id, "error": jsonrpc2.Error{Code: jsonrpc2.CodeInternalError, Message: "no settings today"}})
+	if log := c.next(); log.Method != "window/logMessage" || !strings.Contains(string(log.Params), "no settings today") {
+		t.Errorf("got %+v, want the registration failure logged", log)
+	}
+
+	c.notify("workspace/didChangeConfiguration", DidChangeConfigurationParams{})
+	s.expectForwarded(t, "workspace/didChangeConfiguration")
+}
+
+func TestDidChangeConfigurationRefreshesSettings(t *testing.T) {
+	s := newTestServer()
+	st, err := s.NewSettings("mydsl", &formatSettings{TabSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{"workspace":{"configuration":true}}}`)
+
+	changes := make(chan SettingsChange, 1)
+	st.Subscribe(func(change SettingsChange) { changes <- change })
+	got := make(chan formatSettings, 1)
+	go func() {
+		settings := formatSettings{}
+		if err := st.Get(context.Background(), "file:///a.dsl", &settings); err != nil {
+			t.Error(err)
+		}
+		got <- settings
+	}()
+	c.respond(c.next().ID, []interface{}{map[string]interface{}{"tabs": true}})
+	if settings, want := <-got, (formatSettings{TabSize: 4, Tabs: true}); settings != want {
+		t.Errorf("got %+v, want %+v", settings, want)
+	}
+
+	c.notify("workspace/didChangeConfiguration", DidChangeConfigurationParams{})
+	s.expectForwarded(t, "workspace/didChangeConfiguration")
+	c.respond(c.next().ID, []interface{}{map[string]interface{}{"tabSize": 2, "tabs": true}})
+	if change := <-changes; len(change.Fields) != 1 || change.Fields[0] != "TabSize" {
+		t.Errorf("got change %+v, want TabSize changed", change)
+	}
+
+	c.notify("workspace/didChangeConfiguration", DidChangeConfigurationParams{})
+	request := c.next()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "error": jsonrpc2.Error{Code: jsonrpc2.CodeInternalError, Message: "no configuration today"}})
+	if log := c.next(); log.Method != "window/logMessage" || !strings.Contains(string(log.Params), "no configuration today") {
+		t.Errorf("got %+v, want the refresh failure logged", log)
+	}
+}
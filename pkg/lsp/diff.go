@@ -0,0 +1,272 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+//maxRefinedHunk is the size in bytes above which a changed block of lines is replaced as a whole rather than diffed character by character
+const maxRefinedHunk = 10000
+
+//maxEditDistance bounds the number of differences Myers' algorithm looks for before giving up on finding a minimal diff
+const maxEditDistance = 2000
+
+//ComputeEdits returns a minimal list of non-overlapping edits, in document order, that turn `before` into `after`.
+//Lines are diffed first, and each block of changed lines is then diffed character by character.
+//Positions are in UTF-16 code units, as the LSP requires
+func ComputeEdits(before, after string) []TextEdit {
+	edits := []TextEdit{}
+	if before == after {
+		return edits
+	}
+	a, b := splitLines(before), splitLines(after)
+	aOffsets, bOffsets := lineOffsets(a), lineOffsets(b)
+	cursor := positionCursor{text: before}
+	for _, h := range myersDiff(len(a), len(b), func(i, j int) bool { return a[i] == b[j] }) {
+		start, end := aOffsets[h.aStart], aOffsets[h.aEnd]
+		oldText, newText := before[start:end], after[bOffsets[h.bStart]:bOffsets[h.bEnd]]
+		if len(oldText)+len(newText) > maxRefinedHunk {
+			edits = append(edits, cursor.edit(start, end, newText))
+			continue
+		}
+		oldChars, newChars := splitCharacters(oldText), splitCharacters(newText)
+		oldCharOffsets, newCharOffsets := lineOffsets(oldChars), lineOffsets(newChars)
+		for _, r := range myersDiff(len(oldChars), len(newChars), func(i, j int) bool { return oldChars[i] == newChars[j] }) {
+			edits = append(edits, cursor.edit(start+oldCharOffsets[r.aStart], start+oldCharOffsets[r.aEnd],
+				newText[newCharOffsets[r.bStart]:newCharOffsets[r.bEnd]]))
+		}
+	}
+	return edits
+}
+
+//ApplyEdits applies `edits` to `text`. Edits may be given in any order, but must not overlap; insertions at the same position are applied in the order given
+func ApplyEdits(text string, edits []TextEdit) (string, error) {
+	if err := validateEdits(edits); err != nil {
+		return "", fmt.Errorf("conflicting edits: %v", err)
+	}
+	type span struct {
+		start, end int
+		newText    string
+	}
+	spans := make([]span, len(edits))
+	for i, edit := range edits {
+		start, err := code.OffsetAt(text, edit.Range.Start)
+		if err != nil {
+			return "", err
+		}
+		end, err := code.OffsetAt(text, edit.Range.End)
+		if err != nil {
+			return "", err
+		}
+		spans[i] = span{start: start, end: end, newText: edit.NewText}
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start == spans[j].start {
+			return spans[i].start == spans[i].end && spans[j].start != spans[j].end
+		}
+		return spans[i].start < spans[j].start
+	})
+	var result strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			//distinct positions past the end of a line can clamp to the same offset
+			return "", fmt.Errorf("conflicting edits at offset %d", s.start)
+		}
+		result.WriteString(text[last:s.start])
+		result.WriteString(s.newText)
+		last = s.end
+	}
+	result.WriteString(text[last:])
+	return result.String(), nil
+}
+
+//hunk is a block where two sequences differ: elements [aStart, aEnd) of the first are replaced by elements [bStart, bEnd) of the second
+type hunk struct {
+	aStart, aEnd, bStart, bEnd int
+}
+
+//myersDiff computes the hunks where sequences of lengths `n` and `m` differ with Myers' O(ND) algorithm,
+//where `equal(i, j)` compares element i of the first sequence with element j of the second
+func myersDiff(n, m int, equal func(i, j int) bool) []hunk {
+	//the common prefix and suffix are matched up front, leaving the algorithm to work on the part in between
+	prefix := 0
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+	hunks := []hunk{}
+	for _, h := range myersMiddle(n-prefix-suffix, m-prefix-suffix, func(i, j int) bool { return equal(prefix+i, prefix+j) }) {
+		hunks = append(hunks, hunk{aStart: prefix + h.aStart, aEnd: prefix + h.aEnd, bStart: prefix + h.bStart, bEnd: prefix + h.bEnd})
+	}
+	return hunks
+}
+
+//myersMiddle runs Myers' algorithm proper. The trace it keeps grows with the square of the number of differences,
+//so past `maxEditDistance` differences the sequences are reported as a single hunk instead
+func myersMiddle(n, m int, equal func(i, j int) bool) []hunk {
+	if n == 0 && m == 0 {
+		return []hunk{}
+	}
+	max := n + m
+	if max > maxEditDistance {
+		max = maxEditDistance
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	//trace holds, for each number of edits d, the furthest reaching x of each diagonal k in [-d, d] before step d
+	trace := [][]int{}
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		trace = append(trace, append([]int{}, v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && equal(x, y) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return []hunk{{aEnd: n, bEnd: m}}
+	}
+
+	//walk back through the trace, recording whether each element of both sequences is matched
+	aMatched, bMatched := make([]bool, n), make([]bool, m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		previous := trace[d]
+		at := func(k int) int { return previous[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			aMatched[x], bMatched[y] = true, true
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		aMatched[x], bMatched[y] = true, true
+	}
+
+	hunks := []hunk{}
+	i, j := 0, 0
+	for i < n || j < m {
+		if i < n && j < m && aMatched[i] && bMatched[j] {
+			i++
+			j++
+			continue
+		}
+		h := hunk{aStart: i, bStart: j}
+		for i < n && !aMatched[i] {
+			i++
+		}
+		for j < m && !bMatched[j] {
+			j++
+		}
+		h.aEnd, h.bEnd = i, j
+		hunks = append(hunks, h)
+	}
+	return hunks
+}
+
+//splitLines splits text into lines, each keeping its line terminator of "\n", "\r\n" or a lone "\r"
+func splitLines(text string) []string {
+	lines := []string{}
+	for start := 0; start < len(text); {
+		i := strings.IndexAny(text[start:], "\r\n")
+		if i < 0 {
+			lines = append(lines, text[start:])
+			break
+		}
+		end := start + i + 1
+		if text[end-1] == '\r' && end < len(text) && text[end] == '\n' {
+			end++
+		}
+		lines = append(lines, text[start:end])
+		start = end
+	}
+	return lines
+}
+
+//splitCharacters splits text into characters, keeping `\r\n` together since no position can address the gap between them
+func splitCharacters(text string) []string {
+	chars := make([]string, 0, len(text))
+	for i := 0; i < len(text); {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		if strings.HasPrefix(text[i:], "\r\n") {
+			size = 2
+		}
+		chars = append(chars, text[i:i+size])
+		i += size
+	}
+	return chars
+}
+
+//lineOffsets returns the byte offset of the start of each of the consecutive pieces of a text, followed by the length of the text
+func lineOffsets(pieces []string) []int {
+	offsets := make([]int, len(pieces)+1)
+	for i, piece := range pieces {
+		offsets[i+1] = offsets[i] + len(piece)
+	}
+	return offsets
+}
+
+//positionCursor converts increasing byte offsets of a text to positions without rescanning the text from the start
+type positionCursor struct {
+	text      string
+	offset    int
+	line      int64
+	lineStart int
+}
+
+func (c *positionCursor) position(offset int) code.Position {
+	for ; c.offset < offset; c.offset++ {
+		switch c.text[c.offset] {
+		case '\r':
+			if c.offset+1 < len(c.text) && c.text[c.offset+1] == '\n' {
+				continue
+			}
+			fallthrough
+		case '\n':
+			c.line++
+			c.lineStart = c.offset + 1
+		}
+	}
+	return code.Position{Line: c.line, Character: code.UTF16Len(c.text[c.lineStart:offset])}
+}
+
+func (c *positionCursor) edit(start, end int, newText string) TextEdit {
+	return TextEdit{
+		Range:   code.Range{Start: c.position(start), End: c.position(end)},
+		NewText: newText,
+	}
+}
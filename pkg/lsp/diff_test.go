@@ -0,0 +1,129 @@
+package lsp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMyersDiff(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []hunk
+	}{
+		{a: "", b: "", want: []hunk{}},
+		{a: "abc", b: "abc", want: []hunk{}},
+		{a: "", b: "abc", want: []hunk{{0, 0, 0, 3}}},
+		{a: "abc", b: "", want: []hunk{{0, 3, 0, 0}}},
+		{a: "abc", b: "abxc", want: []hunk{{2, 2, 2, 3}}},
+		{a: "abxc", b: "abc", want: []hunk{{2, 3, 2, 2}}},
+		{a: "abc", b: "axc", want: []hunk{{1, 2, 1, 2}}},
+		{a: "abcabba", b: "cbabac", want: []hunk{{0, 2, 0, 0}, {3, 3, 1, 2}, {5, 6, 4, 4}, {7, 7, 5, 6}}},
+	}
+	for _, tt := range tests {
+		got := myersDiff(len(tt.a), len(tt.b), func(i, j int) bool { return tt.a[i] == tt.b[j] })
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("myersDiff(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		cost := 0
+		for _, h := range got {
+			cost += h.aEnd - h.aStart + h.bEnd - h.bStart
+		}
+		want := 0
+		for _, h := range tt.want {
+			want += h.aEnd - h.aStart + h.bEnd - h.bStart
+		}
+		if cost != want {
+			t.Errorf("myersDiff(%q, %q) costs %d, want the minimal %d", tt.a, tt.b, cost, want)
+		}
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		edits []TextEdit
+		want  string
+		err   bool
+	}{
+		{name: "no edits", text: "abc", want: "abc"},
+		{name: "replace", text: "hello world", edits: []TextEdit{edit(rng(0, 6, 0, 11), "there")}, want: "hello there"},
+		{name: "any order", text: "a\nb\nc", edits: []TextEdit{edit(rng(2, 0, 2, 1), "C"), edit(rng(0, 0, 0, 1), "A")}, want: "A\nb\nC"},
+		{name: "insertions keep their order", text: "ab", edits: []TextEdit{edit(rng(0, 1, 0, 1), "1"), edit(rng(0, 1, 0, 1), "2")}, want: "a12b"},
+		{name: "insertion before a replacement", text: "abc", edits: []TextEdit{edit(rng(0, 1, 0, 2), "X"), edit(rng(0, 1, 0, 1), "+")}, want: "a+Xc"},
+		{name: "append without a final newline", text: "abc", edits: []TextEdit{edit(rng(1, 0, 1, 0), "\ndef")}, want: "abc\ndef"},
+		{name: "delete lines", text: "a\nb\nc\n", edits: []TextEdit{edit(rng(1, 0, 2, 0), "")}, want: "a\nc\n"},
+		{name: "column past the end of the line", text: "ab\r\ncd", edits: []TextEdit{edit(rng(0, 9, 0, 9), "!")}, want: "ab!\r\ncd"},
+		{name: "non-BMP characters", text: "a😀b", edits: []TextEdit{edit(rng(0, 1, 0, 3), "🎉")}, want: "a🎉b"},
+		{name: "overlap", text: "abc", edits: []TextEdit{edit(rng(0, 0, 0, 2), "x"), edit(rng(0, 1, 0, 3), "y")}, err: true},
+		{name: "clamped overlap", text: "ab\ncd", edits: []TextEdit{edit(rng(0, 1, 0, 5), "x"), edit(rng(0, 4, 1, 1), "y")}, err: true},
+		{name: "line past the end", text: "abc", edits: []TextEdit{edit(rng(5, 0, 5, 0), "x")}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyEdits(tt.text, tt.edits)
+			if tt.err {
+				if err == nil {
+					t.Errorf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeEditsRoundTrip(t *testing.T) {
+	texts := []string{
+		"",
+		"\n",
+		"one line",
+		"one\ntwo\nthree\n",
+		"one\r\ntwo\r\nthree\r\n",
+		"one\rtwo\rthree",
+		"😀 smile\n🎉 party\n",
+		"a😀b😀c",
+		"func main() {\n\tprintln(\"hello\")\n}\n",
+		strings.Repeat("x", maxRefinedHunk) + "\n",
+	}
+	for _, before := range texts {
+		for _, after := range texts {
+			edits := ComputeEdits(before, after)
+			if err := validateEdits(edits); err != nil {
+				t.Errorf("ComputeEdits(%q, %q) returned invalid edits: %v", abbreviate(before), abbreviate(after), err)
+				continue
+			}
+			got, err := ApplyEdits(before, edits)
+			if err != nil || got != after {
+				t.Errorf("applying ComputeEdits(%q, %q) = %v gave %q, %v", abbreviate(before), abbreviate(after), edits, abbreviate(got), err)
+			}
+		}
+	}
+}
+
+func TestComputeEditsIsMinimal(t *testing.T) {
+	tests := []struct {
+		before, after string
+		want          []TextEdit
+	}{
+		{before: "", after: "", want: []TextEdit{}},
+		{before: "one\ntwo\n", after: "one\ntoo\n", want: []TextEdit{edit(rng(1, 1, 1, 2), "o")}},
+		{before: "a\r\nb\r\n", after: "a\r\nb\r\nc\r\n", want: []TextEdit{edit(rng(2, 0, 2, 0), "c\r\n")}},
+		{before: "😀x", after: "😀y", want: []TextEdit{edit(rng(0, 2, 0, 3), "y")}},
+	}
+	for _, tt := range tests {
+		if got := ComputeEdits(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ComputeEdits(%q, %q) = %v, want %v", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func abbreviate(s string) string {
+	if len(s) > 40 {
+		return s[:40] + "..."
+	}
+	return s
+}
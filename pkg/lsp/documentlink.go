@@ -0,0 +1,175 @@
+package lsp
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//DocumentLinkParams are the parameters of a `textDocument/documentLink` request
+type DocumentLinkParams struct {
+	//The document to provide document links for
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+//DocumentLink is a range in a text document that links to an internal or external resource, like another text document or a web site
+type DocumentLink struct {
+	//Range this link applies to
+	Range code.Range `json:"range"`
+	//Target is the uri this link points to. If missing a resolve request is sent later
+	Target *code.DocumentURI `json:"target,omitempty"`
+	//Tooltip shown when hovering over this link. Only sent to clients with tooltip support
+	Tooltip *string `json:"tooltip,omitempty"`
+	//Data is preserved between a `textDocument/documentLink` and a `documentLink/resolve` request
+	Data *json.RawMessage `json:"data,omitempty"`
+}
+
+//DocumentLinkOptions are the server capabilities for document links
+type DocumentLinkOptions struct {
+	*WorkDoneProgressOptions
+	//ResolveProvider indicates that document links have a resolve provider as well
+	ResolveProvider *bool `json:"resolveProvider,omitempty"`
+}
+
+//DocumentLinkProvider is implemented by embedding servers that compute document links for `textDocument/documentLink`
+type DocumentLinkProvider interface {
+	DocumentLinks(params *DocumentLinkParams) ([]DocumentLink, error)
+}
+
+//DocumentLinkResolver is optionally implemented by a `DocumentLinkProvider` to compute the target of a link lazily on `documentLink/resolve`
+type DocumentLinkResolver interface {
+	ResolveDocumentLink(link *DocumentLink) (*DocumentLink, error)
+}
+
+func (s *DefaultServer) documentLink(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DocumentLinkProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := DocumentLinkParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	links, err := provider.DocumentLinks(&params)
+	if err == nil && !s.supportsLinkTooltips() {
+		for i := range links {
+			links[i].Tooltip = nil
+		}
+	}
+	s.reply(req, links, err)
+}
+
+func (s *DefaultServer) resolveDocumentLink(req *jsonrpc2.Request) {
+	resolver, ok := s.provider().(DocumentLinkResolver)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	link := DocumentLink{}
+	if err := decodeParams(req, &link); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	resolved, err := resolver.ResolveDocumentLink(&link)
+	if err == nil && resolved != nil && !s.supportsLinkTooltips() {
+		resolved.Tooltip = nil
+	}
+	s.reply(req, resolved, err)
+}
+
+func (s *DefaultServer) supportsLinkTooltips() bool {
+	tdc := s.ClientCapabilities().TextDocumentCapabilities
+	return tdc != nil && tdc.DocumentLink != nil && tdc.DocumentLink.TooltipSupport != nil && *tdc.DocumentLink.TooltipSupport
+}
+
+var (
+	urlPattern          = regexp.MustCompile(`\b(?:https?|ftp|file)://[^\s<>"'` + "`" + `]+`)
+	relativePathPattern = regexp.MustCompile(`(?:^|[\s"'(\[<=:])(\.\.?/[^\s<>"'` + "`" + `()\[\]]+)`)
+)
+
+//DetectDocumentLinks finds URLs and relative file paths (starting with `./` or `../`) in `text`.
+//Relative paths are resolved against the workspace `root`, and are skipped if `root` is empty or not a valid URI.
+//Trailing punctuation such as a full stop ending a sentence is not considered part of a link
+func DetectDocumentLinks(text string, root code.DocumentURI) []DocumentLink {
+	links := []DocumentLink{}
+	var base *url.URL
+	if root != "" {
+		if u, err := url.Parse(string(root)); err == nil {
+			//the root is a folder, so relative paths resolve inside it rather than next to it
+			if !strings.HasSuffix(u.Path, "/") {
+				u.Path += "/"
+				u.RawPath = ""
+			}
+			base = u
+		}
+	}
+	for lineNumber, line := range code.Lines(text) {
+		taken := [][]int{}
+		for _, loc := range urlPattern.FindAllStringIndex(line, -1) {
+			start, end := loc[0], trimLinkEnd(line, loc[0], loc[1])
+			target := code.DocumentURI(line[start:end])
+			links = append(links, newDocumentLink(line, lineNumber, start, end, target))
+			taken = append(taken, []int{start, end})
+		}
+		if base == nil {
+			continue
+		}
+		for _, loc := range relativePathPattern.FindAllStringSubmatchIndex(line, -1) {
+			start, end := loc[2], trimLinkEnd(line, loc[2], loc[3])
+			if overlapsAny(taken, start, end) {
+				continue
+			}
+			ref, err := url.Parse(line[start:end])
+			if err != nil {
+				continue
+			}
+			target := base.ResolveReference(ref)
+			links = append(links, newDocumentLink(line, lineNumber, start, end, code.DocumentURI(target.String())))
+		}
+	}
+	return links
+}
+
+func newDocumentLink(line string, lineNumber, start, end int, target code.DocumentURI) DocumentLink {
+	return DocumentLink{
+		Range: code.Range{
+			Start: code.Position{Line: int64(lineNumber), Character: code.UTF16Len(line[:start])},
+			End:   code.Position{Line: int64(lineNumber), Character: code.UTF16Len(line[:end])},
+		},
+		Target: &target,
+	}
+}
+
+//trimLinkEnd drops trailing punctuation from the link spanning line[start:end].
+//Closing brackets are kept when they balance an opening bracket inside the link, as in `https://en.wikipedia.org/wiki/Go_(language)`
+func trimLinkEnd(line string, start, end int) int {
+	for end > start {
+		switch c := line[end-1]; c {
+		case ')', ']', '}':
+			open := map[byte]byte{')': '(', ']': '[', '}': '{'}[c]
+			if strings.Count(line[start:end], string(open)) >= strings.Count(line[start:end], string(c)) {
+				return end
+			}
+		case '.', ',', ';', ':', '!', '?':
+		default:
+			return end
+		}
+		end--
+	}
+	return end
+}
+
+func overlapsAny(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+func TestDetectDocumentLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		root code.DocumentURI
+		want map[code.Range]code.DocumentURI
+	}{
+		{
+			name: "url ending a sentence",
+			text: "see https://example.com/docs.",
+			want: map[code.Range]code.DocumentURI{rng(0, 4, 0, 28): "https://example.com/docs"},
+		},
+		{
+			name: "balanced brackets",
+			text: "(https://en.wikipedia.org/wiki/Go_(language))",
+			want: map[code.Range]code.DocumentURI{rng(0, 1, 0, 44): "https://en.wikipedia.org/wiki/Go_(language)"},
+		},
+		{
+			name: "relative path",
+			text: "import ./lib/util.dsl\nimport ../shared.dsl",
+			root: "file:///ws/project",
+			want: map[code.Range]code.DocumentURI{
+				rng(0, 7, 0, 21): "file:///ws/project/lib/util.dsl",
+				rng(1, 7, 1, 20): "file:///ws/shared.dsl",
+			},
+		},
+		{
+			name: "relative path with query and fragment",
+			text: "see ./guide.md?plain=1#usage",
+			root: "file:///ws/",
+			want: map[code.Range]code.DocumentURI{rng(0, 4, 0, 28): "file:///ws/guide.md?plain=1#usage"},
+		},
+		{
+			name: "relative path without root",
+			text: "import ./lib/util.dsl",
+		},
+		{
+			name: "line endings",
+			text: "a https://example.com/a\rb https://example.com/b\r\nc https://example.com/c.\r\n",
+			want: map[code.Range]code.DocumentURI{
+				rng(0, 2, 0, 23): "https://example.com/a",
+				rng(1, 2, 1, 23): "https://example.com/b",
+				rng(2, 2, 2, 23): "https://example.com/c",
+			},
+		},
+		{
+			name: "utf-16 columns",
+			text: "😀 https://example.com",
+			want: map[code.Range]code.DocumentURI{rng(0, 3, 0, 22): "https://example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[code.Range]code.DocumentURI{}
+			for _, link := range DetectDocumentLinks(tt.text, tt.root) {
+				got[link.Range] = *link.Target
+			}
+			if tt.want == nil {
+				tt.want = map[code.Range]code.DocumentURI{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
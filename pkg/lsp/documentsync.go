@@ -0,0 +1,47 @@
+package lsp
+
+import (
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//documentListener is implemented by caches of the server that must follow the lifecycle of open documents
+type documentListener interface {
+	DocumentChanged(uri code.DocumentURI, version int64)
+	DocumentClosed(uri code.DocumentURI)
+}
+
+//addDocumentListener has `listener` notified of `textDocument/didChange` and `textDocument/didClose`
+func (s *DefaultServer) addDocumentListener(listener documentListener) {
+	s.listenerMutex.Lock()
+	defer s.listenerMutex.Unlock()
+	s.documentListeners = append(s.documentListeners, listener)
+}
+
+func (s *DefaultServer) listeners() []documentListener {
+	s.listenerMutex.Lock()
+	defer s.listenerMutex.Unlock()
+	return append([]documentListener(nil), s.documentListeners...)
+}
+
+//didChangeTextDocument notifies the document listeners of the new version of a document, and forwards the notification to the embedding server
+func (s *DefaultServer) didChangeTextDocument(req *jsonrpc2.Request) {
+	params := DidChangeTextDocumentParams{}
+	if err := decodeParams(req, &params); err == nil && params.TextDocument.Version != nil {
+		for _, listener := range s.listeners() {
+			listener.DocumentChanged(params.TextDocument.URI, *params.TextDocument.Version)
+		}
+	}
+	s.forward(req)
+}
+
+//didCloseTextDocument notifies the document listeners that a document was closed, and forwards the notification to the embedding server
+func (s *DefaultServer) didCloseTextDocument(req *jsonrpc2.Request) {
+	params := DidCloseTextDocumentParams{}
+	if err := decodeParams(req, &params); err == nil {
+		for _, listener := range s.listeners() {
+			listener.DocumentClosed(params.TextDocument.URI)
+		}
+	}
+	s.forward(req)
+}
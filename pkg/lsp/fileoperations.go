@@ -0,0 +1,367 @@
+package lsp
+
+import (
+	"net/url"
+	"os"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//FileCreate represents information on a file or folder that is created
+type FileCreate struct {
+	//URI of the file or folder
+	URI code.DocumentURI `json:"uri"`
+}
+
+//FileRename represents information on a file or folder that is renamed
+type FileRename struct {
+	//OldURI is the original location of the file or folder
+	OldURI code.DocumentURI `json:"oldUri"`
+	//NewURI is the new location of the file or folder
+	NewURI code.DocumentURI `json:"newUri"`
+}
+
+//FileDelete represents information on a file or folder that is deleted
+type FileDelete struct {
+	//URI of the file or folder
+	URI code.DocumentURI `json:"uri"`
+}
+
+//CreateFilesParams are the parameters of the `workspace/willCreateFiles` request and the `workspace/didCreateFiles` notification
+type CreateFilesParams struct {
+	Files []FileCreate `json:"files"`
+}
+
+//RenameFilesParams are the parameters of the `workspace/willRenameFiles` request and the `workspace/didRenameFiles` notification
+type RenameFilesParams struct {
+	Files []FileRename `json:"files"`
+}
+
+//DeleteFilesParams are the parameters of the `workspace/willDeleteFiles` request and the `workspace/didDeleteFiles` notification
+type DeleteFilesParams struct {
+	Files []FileDelete `json:"files"`
+}
+
+//FileOperationPatternKind restricts a file operation pattern to files or to folders
+type FileOperationPatternKind string
+
+//The file operation pattern kinds
+const (
+	FileOperationPatternKindFile   FileOperationPatternKind = "file"
+	FileOperationPatternKindFolder FileOperationPatternKind = "folder"
+)
+
+//FileOperationPatternOptions are matching options for a file operation pattern
+type FileOperationPatternOptions struct {
+	//IgnoreCase makes the glob match case insensitive
+	IgnoreCase *bool `json:"ignoreCase,omitempty"`
+}
+
+//FileOperationPattern describes the files a file operation is of interest for
+type FileOperationPattern struct {
+	//Glob pattern to match, see `Glob` for the syntax
+	Glob string `json:"glob"`
+	//Matches restricts the pattern to files or to folders. If missing both are matched.
+	//The restriction is not applied to files about to be created or already deleted, whose kind cannot be checked
+	Matches *FileOperationPatternKind `json:"matches,omitempty"`
+	//Options of the pattern
+	Options *FileOperationPatternOptions `json:"options,omitempty"`
+}
+
+//FileOperationFilter filters file operations by URI scheme and path
+type FileOperationFilter struct {
+	//Scheme of the URIs the filter applies to, e.g. `file`. If missing all schemes match
+	Scheme *string `json:"scheme,omitempty"`
+	//Pattern the path of the URIs must match
+	Pattern FileOperationPattern `json:"pattern"`
+}
+
+//FileOperationRegistrationOptions are the options to register for a file operation
+type FileOperationRegistrationOptions struct {
+	//Filters of the operation. The server is notified of an operation if any filter matches
+	Filters []FileOperationFilter `json:"filters"`
+}
+
+//FileOperationOptions are the server capabilities for file operations
+type FileOperationOptions struct {
+	DidCreate  *FileOperationRegistrationOptions `json:"didCreate,omitempty"`
+	WillCreate *FileOperationRegistrationOptions `json:"willCreate,omitempty"`
+	DidRename  *FileOperationRegistrationOptions `json:"didRename,omitempty"`
+	WillRename *FileOperationRegistrationOptions `json:"willRename,omitempty"`
+	DidDelete  *FileOperationRegistrationOptions `json:"didDelete,omitempty"`
+	WillDelete *FileOperationRegistrationOptions `json:"willDelete,omitempty"`
+}
+
+//WillCreateFilesProvider is implemented by embedding servers that want to edit the workspace before files matching its filters are created
+type WillCreateFilesProvider interface {
+	WillCreateFilesFilters() []FileOperationFilter
+	WillCreateFiles(params *CreateFilesParams) (*WorkspaceEdit, error)
+}
+
+//DidCreateFilesProvider is implemented by embedding servers notified when files matching its filters have been created
+type DidCreateFilesProvider interface {
+	DidCreateFilesFilters() []FileOperationFilter
+	DidCreateFiles(params *CreateFilesParams)
+}
+
+//WillRenameFilesProvider is implemented by embedding servers that want to edit the workspace before files matching its filters are renamed,
+//for example to update imports
+type WillRenameFilesProvider interface {
+	WillRenameFilesFilters() []FileOperationFilter
+	WillRenameFiles(params *RenameFilesParams) (*WorkspaceEdit, error)
+}
+
+//DidRenameFilesProvider is implemented by embedding servers notified when files matching its filters have been renamed
+type DidRenameFilesProvider interface {
+	DidRenameFilesFilters() []FileOperationFilter
+	DidRenameFiles(params *RenameFilesParams)
+}
+
+//WillDeleteFilesProvider is implemented by embedding servers that want to edit the workspace before files matching its filters are deleted
+type WillDeleteFilesProvider interface {
+	WillDeleteFilesFilters() []FileOperationFilter
+	WillDeleteFiles(params *DeleteFilesParams) (*WorkspaceEdit, error)
+}
+
+//DidDeleteFilesProvider is implemented by embedding servers notified when files matching its filters have been deleted
+type DidDeleteFilesProvider interface {
+	DidDeleteFilesFilters() []FileOperationFilter
+	DidDeleteFiles(params *DeleteFilesParams)
+}
+
+//fileOperationsCapability returns the file operations the embedding server registers for, or nil if there are none
+func (s *DefaultServer) fileOperationsCapability() *FileOperationOptions {
+	provider := s.provider()
+	options := FileOperationOptions{}
+	registered := false
+	register := func(filters []FileOperationFilter) *FileOperationRegistrationOptions {
+		registered = true
+		return &FileOperationRegistrationOptions{Filters: filters}
+	}
+	if p, ok := provider.(DidCreateFilesProvider); ok {
+		options.DidCreate = register(p.DidCreateFilesFilters())
+	}
+	if p, ok := provider.(WillCreateFilesProvider); ok {
+		options.WillCreate = register(p.WillCreateFilesFilters())
+	}
+	if p, ok := provider.(DidRenameFilesProvider); ok {
+		options.DidRename = register(p.DidRenameFilesFilters())
+	}
+	if p, ok := provider.(WillRenameFilesProvider); ok {
+		options.WillRename = register(p.WillRenameFilesFilters())
+	}
+	if p, ok := provider.(DidDeleteFilesProvider); ok {
+		options.DidDelete = register(p.DidDeleteFilesFilters())
+	}
+	if p, ok := provider.(WillDeleteFilesProvider); ok {
+		options.WillDelete = register(p.WillDeleteFilesFilters())
+	}
+	if !registered {
+		return nil
+	}
+	return &options
+}
+
+//Matches reports whether the file or folder at `uri` matches the filter. Filters restricted to files or folders
+//only exclude URIs of the other kind when these exist on the local file system.
+//The glob is compiled on each call: the server compiles the filters of its providers once, on first use
+func (f FileOperationFilter) Matches(uri code.DocumentURI) bool {
+	return compileFileOperationFilter(f).matches(uri, true)
+}
+
+//compiledFileOperationFilter is a file operation filter with its glob compiled, or a nil glob if the pattern is invalid and matches nothing
+type compiledFileOperationFilter struct {
+	FileOperationFilter
+	glob *Glob
+}
+
+func compileFileOperationFilter(f FileOperationFilter) compiledFileOperationFilter {
+	ignoreCase := f.Pattern.Options != nil && f.Pattern.Options.IgnoreCase != nil && *f.Pattern.Options.IgnoreCase
+	glob, _ := CompileGlob(f.Pattern.Glob, ignoreCase)
+	return compiledFileOperationFilter{FileOperationFilter: f, glob: glob}
+}
+
+//matches reports whether `uri` matches the filter. The kind of the file or folder is only checked if `checkKind` is set,
+//since there is nothing to check before a file is created or after it is deleted
+func (f compiledFileOperationFilter) matches(uri code.DocumentURI, checkKind bool) bool {
+	if f.glob == nil {
+		return false
+	}
+	u, err := url.Parse(string(uri))
+	if err != nil {
+		return false
+	}
+	if f.Scheme != nil && *f.Scheme != u.Scheme {
+		return false
+	}
+	if !f.glob.Match(u.Path) {
+		return false
+	}
+	if checkKind && f.Pattern.Matches != nil && u.Scheme == "file" {
+		if info, err := os.Stat(u.Path); err == nil {
+			return info.IsDir() == (*f.Pattern.Matches == FileOperationPatternKindFolder)
+		}
+	}
+	return true
+}
+
+//fileOperationFilters returns the compiled filters of the file operation `method`, compiling the filters returned by `filters` on first use
+func (s *DefaultServer) fileOperationFilters(method string, filters func() []FileOperationFilter) []compiledFileOperationFilter {
+	if compiled, ok := s.compiledFileOperationFilters[method]; ok {
+		return compiled
+	}
+	compiled := []compiledFileOperationFilter{}
+	for _, f := range filters() {
+		compiled = append(compiled, compileFileOperationFilter(f))
+	}
+	if s.compiledFileOperationFilters == nil {
+		s.compiledFileOperationFilters = make(map[string][]compiledFileOperationFilter)
+	}
+	s.compiledFileOperationFilters[method] = compiled
+	return compiled
+}
+
+func matchesFileOperationFilters(filters []compiledFileOperationFilter, uri code.DocumentURI, checkKind bool) bool {
+	for _, f := range filters {
+		if f.matches(uri, checkKind) {
+			return true
+		}
+	}
+	return false
+}
+
+//filterFileCreates keeps the created files matching the filters. Their kind is only known once they have been created
+func filterFileCreates(filters []compiledFileOperationFilter, files []FileCreate, created bool) []FileCreate {
+	matched := []FileCreate{}
+	for _, f := range files {
+		if matchesFileOperationFilters(filters, f.URI, created) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+//filterFileRenames keeps the renames whose old or new location matches the filters. The kind is checked at whichever location exists
+func filterFileRenames(filters []compiledFileOperationFilter, files []FileRename) []FileRename {
+	matched := []FileRename{}
+	for _, f := range files {
+		if matchesFileOperationFilters(filters, f.OldURI, true) || matchesFileOperationFilters(filters, f.NewURI, true) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+//filterFileDeletes keeps the deleted files matching the filters. Their kind is only known until they have been deleted
+func filterFileDeletes(filters []compiledFileOperationFilter, files []FileDelete, deleted bool) []FileDelete {
+	matched := []FileDelete{}
+	for _, f := range files {
+		if matchesFileOperationFilters(filters, f.URI, !deleted) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+func (s *DefaultServer) willCreateFiles(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(WillCreateFilesProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := CreateFilesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	if params.Files = filterFileCreates(s.fileOperationFilters(req.Method, provider.WillCreateFilesFilters), params.Files, false); len(params.Files) == 0 {
+		s.reply(req, nil, nil)
+		return
+	}
+	edit, err := provider.WillCreateFiles(&params)
+	s.reply(req, edit, err)
+}
+
+func (s *DefaultServer) didCreateFiles(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DidCreateFilesProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := CreateFilesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		return
+	}
+	if params.Files = filterFileCreates(s.fileOperationFilters(req.Method, provider.DidCreateFilesFilters), params.Files, true); len(params.Files) > 0 {
+		provider.DidCreateFiles(&params)
+	}
+}
+
+func (s *DefaultServer) willRenameFiles(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(WillRenameFilesProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := RenameFilesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	if params.Files = filterFileRenames(s.fileOperationFilters(req.Method, provider.WillRenameFilesFilters), params.Files); len(params.Files) == 0 {
+		s.reply(req, nil, nil)
+		return
+	}
+	edit, err := provider.WillRenameFiles(&params)
+	s.reply(req, edit, err)
+}
+
+func (s *DefaultServer) didRenameFiles(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DidRenameFilesProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := RenameFilesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		return
+	}
+	if params.Files = filterFileRenames(s.fileOperationFilters(req.Method, provider.DidRenameFilesFilters), params.Files); len(params.Files) > 0 {
+		provider.DidRenameFiles(&params)
+	}
+}
+
+func (s *DefaultServer) willDeleteFiles(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(WillDeleteFilesProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := DeleteFilesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	if params.Files = filterFileDeletes(s.fileOperationFilters(req.Method, provider.WillDeleteFilesFilters), params.Files, false); len(params.Files) == 0 {
+		s.reply(req, nil, nil)
+		return
+	}
+	edit, err := provider.WillDeleteFiles(&params)
+	s.reply(req, edit, err)
+}
+
+func (s *DefaultServer) didDeleteFiles(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DidDeleteFilesProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := DeleteFilesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		return
+	}
+	if params.Files = filterFileDeletes(s.fileOperationFilters(req.Method, provider.DidDeleteFilesFilters), params.Files, true); len(params.Files) > 0 {
+		provider.DidDeleteFiles(&params)
+	}
+}
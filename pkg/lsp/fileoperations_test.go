@@ -0,0 +1,89 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+func TestFileOperationFilterMatches(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "folder.dsl"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file.dsl"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, folder := FileOperationPatternKindFile, FileOperationPatternKindFolder
+	scheme, yes := "file", true
+	uri := func(name string) code.DocumentURI { return code.URIFromPath(filepath.Join(dir, name)) }
+	tests := []struct {
+		name   string
+		filter FileOperationFilter
+		uri    code.DocumentURI
+		want   bool
+	}{
+		{name: "glob", filter: FileOperationFilter{Pattern: FileOperationPattern{Glob: "**/*.dsl"}}, uri: uri("file.dsl"), want: true},
+		{name: "glob mismatch", filter: FileOperationFilter{Pattern: FileOperationPattern{Glob: "**/*.go"}}, uri: uri("file.dsl")},
+		{name: "scheme", filter: FileOperationFilter{Scheme: &scheme, Pattern: FileOperationPattern{Glob: "**"}}, uri: "untitled:/a.dsl"},
+		{
+			name:   "ignore case",
+			filter: FileOperationFilter{Pattern: FileOperationPattern{Glob: "**/*.DSL", Options: &FileOperationPatternOptions{IgnoreCase: &yes}}},
+			uri:    uri("file.dsl"),
+			want:   true,
+		},
+		{name: "file", filter: FileOperationFilter{Pattern: FileOperationPattern{Glob: "**/*.dsl", Matches: &file}}, uri: uri("file.dsl"), want: true},
+		{name: "not a folder", filter: FileOperationFilter{Pattern: FileOperationPattern{Glob: "**/*.dsl", Matches: &folder}}, uri: uri("file.dsl")},
+		{name: "folder", filter: FileOperationFilter{Pattern: FileOperationPattern{Glob: "**/*.dsl", Matches: &folder}}, uri: uri("folder.dsl"), want: true},
+		{name: "missing file", filter: FileOperationFilter{Pattern: FileOperationPattern{Glob: "**/*.dsl", Matches: &folder}}, uri: uri("missing.dsl"), want: true},
+		{name: "invalid glob", filter: FileOperationFilter{Pattern: FileOperationPattern{Glob: "{"}}, uri: uri("file.dsl")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.uri); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+//fileOperationsServer is interested in folders only, and counts how often its filters are requested
+type fileOperationsServer struct {
+	*testServer
+	filterCalls int
+	deleted     chan []FileDelete
+}
+
+func (s *fileOperationsServer) DidDeleteFilesFilters() []FileOperationFilter {
+	s.filterCalls++
+	folder := FileOperationPatternKindFolder
+	return []FileOperationFilter{{Pattern: FileOperationPattern{Glob: "**/*.dsl", Matches: &folder}}}
+}
+
+func (s *fileOperationsServer) DidDeleteFiles(params *DeleteFilesParams) {
+	s.deleted <- params.Files
+}
+
+func TestDidDeleteFilesSkipsKindCheck(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.dsl"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := &fileOperationsServer{testServer: newTestServer(), deleted: make(chan []FileDelete, 10)}
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{}}`)
+	files := []FileDelete{{URI: code.URIFromPath(filepath.Join(dir, "gone.dsl"))}, {URI: code.URIFromPath(filepath.Join(dir, "file.dsl"))}}
+	for i := 0; i < 2; i++ {
+		c.notify("workspace/didDeleteFiles", DeleteFilesParams{Files: files})
+		//the kind of deleted files cannot be checked, so it is not checked for any of them
+		if got := <-s.deleted; !reflect.DeepEqual(got, files) {
+			t.Errorf("got %v, want %v", got, files)
+		}
+	}
+	if s.filterCalls > 2 {
+		t.Errorf("filters requested %d times, want them compiled once", s.filterCalls)
+	}
+}
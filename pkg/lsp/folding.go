@@ -0,0 +1,236 @@
+package lsp
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//FoldingRangeKind is the kind of a folding range, used to classify folding ranges for commands like 'Fold all comments'
+type FoldingRangeKind string
+
+const (
+	//FoldingRangeKindComment is the folding range kind for a comment
+	FoldingRangeKindComment FoldingRangeKind = "comment"
+	//FoldingRangeKindImports is the folding range kind for imports or includes
+	FoldingRangeKindImports FoldingRangeKind = "imports"
+	//FoldingRangeKindRegion is the folding range kind for a region (e.g. `#region`)
+	FoldingRangeKindRegion FoldingRangeKind = "region"
+)
+
+//FoldingRangeParams are the parameters of a `textDocument/foldingRange` request
+type FoldingRangeParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+//FoldingRange represents a folding range. To be valid, start and end line must be bigger than zero and smaller than the number of lines in the document
+type FoldingRange struct {
+	//StartLine is the zero-based start line of the range to fold. The folded area starts after the line's last character
+	StartLine int64 `json:"startLine"`
+	//StartCharacter is the zero-based character offset from where the folded range starts. If not defined, defaults to the length of the start line
+	StartCharacter *int64 `json:"startCharacter,omitempty"`
+	//EndLine is the zero-based end line of the range to fold. The folded area ends with the line's last character
+	EndLine int64 `json:"endLine"`
+	//EndCharacter is the zero-based character offset before the folded range ends. If not defined, defaults to the length of the end line
+	EndCharacter *int64 `json:"endCharacter,omitempty"`
+	//Kind describes the kind of the folding range
+	Kind *FoldingRangeKind `json:"kind,omitempty"`
+}
+
+//FoldingRangeOptions are the server capabilities for folding ranges
+type FoldingRangeOptions struct {
+	*WorkDoneProgressOptions
+}
+
+type foldingRangeUnion struct {
+	Boolean *bool
+	Options *FoldingRangeOptions
+}
+
+func (fu *foldingRangeUnion) MarshalJSON() ([]byte, error) {
+	if fu.Boolean != nil {
+		return json.Marshal(*fu.Boolean)
+	}
+	return json.Marshal(fu.Options)
+}
+
+func (fu *foldingRangeUnion) UnmarshalJSON(js []byte) error {
+	*fu = foldingRangeUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		fu.Boolean = &b
+		return nil
+	}
+	fu.Options = &FoldingRangeOptions{}
+	return json.Unmarshal(js, fu.Options)
+}
+
+//FoldingRangeProvider is implemented by embedding servers that compute folding ranges for `textDocument/foldingRange`.
+//The ranges returned are adapted to the client with `LimitFoldingRanges`. `IndentationFoldingRanges` provides folding based on indentation
+type FoldingRangeProvider interface {
+	FoldingRanges(params *FoldingRangeParams) ([]FoldingRange, error)
+}
+
+func (s *DefaultServer) foldingRange(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(FoldingRangeProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := FoldingRangeParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	ranges, err := provider.FoldingRanges(&params)
+	if err != nil {
+		s.reply(req, nil, err)
+		return
+	}
+	var caps *FoldingRangeClientCapabilities
+	if tdc := s.ClientCapabilities().TextDocumentCapabilities; tdc != nil {
+		caps = tdc.FoldingRange
+	}
+	s.reply(req, LimitFoldingRanges(ranges, caps), nil)
+}
+
+//LimitFoldingRanges adapts folding ranges to the capabilities of the client, which may be nil. The ranges are returned sorted by start line,
+//with only the outermost of ranges starting on the same line kept and partially overlapping ranges merged, so that the ranges nest properly.
+//A range ending on the line another starts is shortened to end on the line before, and dropped if nothing is left to fold.
+//Character offsets are dropped if the client only folds whole lines, in which case single line ranges are dropped too.
+//If the client limits the number of ranges, the outermost ranges are kept
+func LimitFoldingRanges(ranges []FoldingRange, caps *FoldingRangeClientCapabilities) []FoldingRange {
+	lineFoldingOnly := caps != nil && caps.LineFoldingOnly != nil && *caps.LineFoldingOnly
+	sorted := make([]FoldingRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.EndLine < r.StartLine || (lineFoldingOnly && r.EndLine == r.StartLine) {
+			continue
+		}
+		if lineFoldingOnly {
+			r.StartCharacter, r.EndCharacter = nil, nil
+		}
+		sorted = append(sorted, r)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].StartLine == sorted[j].StartLine {
+			return sorted[i].EndLine > sorted[j].EndLine
+		}
+		return sorted[i].StartLine < sorted[j].StartLine
+	})
+
+	result := []FoldingRange{}
+	depths := []int{}
+	dropped := []bool{}
+	//open holds the indices in result of the ranges enclosing the current one, innermost last
+	open := []int{}
+	for _, r := range sorted {
+		for len(open) > 0 && result[open[len(open)-1]].EndLine <= r.StartLine {
+			i := open[len(open)-1]
+			open = open[:len(open)-1]
+			if result[i].EndLine == r.StartLine {
+				//a range ending on the line the next one starts, as in `} else {`, stops on the line before so that both can be folded
+				result[i].EndLine, result[i].EndCharacter = r.StartLine-1, nil
+				dropped[i] = result[i].EndLine <= result[i].StartLine
+			}
+		}
+		if len(result) > 0 && result[len(result)-1].StartLine == r.StartLine {
+			continue
+		}
+		if len(open) > 0 && result[open[len(open)-1]].EndLine < r.EndLine {
+			//merge a range that starts inside another but ends after it into the enclosing ranges
+			for i := len(open) - 1; i >= 0 && result[open[i]].EndLine < r.EndLine; i-- {
+				result[open[i]].EndLine = r.EndLine
+				result[open[i]].EndCharacter = r.EndCharacter
+			}
+			continue
+		}
+		open = append(open, len(result))
+		result = append(result, r)
+		depths = append(depths, len(open))
+		dropped = append(dropped, false)
+	}
+	kept := 0
+	for i := range result {
+		if !dropped[i] {
+			result[kept], depths[kept] = result[i], depths[i]
+			kept++
+		}
+	}
+	result, depths = result[:kept], depths[:kept]
+
+	if caps == nil || caps.RangeLimit == nil || int64(len(result)) <= *caps.RangeLimit {
+		return result
+	}
+	limit := int(*caps.RangeLimit)
+	if limit < 0 {
+		limit = 0
+	}
+	indices := make([]int, len(result))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool { return depths[indices[i]] < depths[indices[j]] })
+	indices = indices[:limit]
+	sort.Ints(indices)
+	limited := make([]FoldingRange, 0, limit)
+	for _, i := range indices {
+		limited = append(limited, result[i])
+	}
+	return limited
+}
+
+//IndentationFoldingRanges computes folding ranges from the indentation of `text`: a line followed by more indented lines folds them.
+//Blank lines do not end a block, and tabs count as `tabSize` columns
+func IndentationFoldingRanges(text string, tabSize int) []FoldingRange {
+	type block struct {
+		indent    int
+		startLine int64
+	}
+	ranges := []FoldingRange{}
+	blocks := []block{}
+	lastLine := int64(-1)
+	closeBlocks := func(indent int) {
+		for len(blocks) > 0 && blocks[len(blocks)-1].indent >= indent {
+			b := blocks[len(blocks)-1]
+			blocks = blocks[:len(blocks)-1]
+			if lastLine > b.startLine {
+				ranges = append(ranges, FoldingRange{StartLine: b.startLine, EndLine: lastLine})
+			}
+		}
+	}
+	for i, line := range code.Lines(text) {
+		indent, blank := indentation(line, tabSize)
+		if blank {
+			continue
+		}
+		closeBlocks(indent)
+		blocks = append(blocks, block{indent: indent, startLine: int64(i)})
+		lastLine = int64(i)
+	}
+	closeBlocks(0)
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].StartLine < ranges[j].StartLine })
+	return ranges
+}
+
+//indentation returns the width of the leading whitespace of a line, and whether the line is blank
+func indentation(line string, tabSize int) (int, bool) {
+	width := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			width++
+		case '\t':
+			if tabSize > 0 {
+				width += tabSize - width%tabSize
+			} else {
+				width++
+			}
+		default:
+			return width, false
+		}
+	}
+	return width, true
+}
@@ -0,0 +1,102 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func lines(pairs ...int64) []FoldingRange {
+	ranges := []FoldingRange{}
+	for i := 0; i < len(pairs); i += 2 {
+		ranges = append(ranges, FoldingRange{StartLine: pairs[i], EndLine: pairs[i+1]})
+	}
+	return ranges
+}
+
+func TestLimitFoldingRanges(t *testing.T) {
+	yes := true
+	limit := func(n int64) *FoldingRangeClientCapabilities {
+		return &FoldingRangeClientCapabilities{RangeLimit: &n}
+	}
+	tests := []struct {
+		name   string
+		ranges []FoldingRange
+		caps   *FoldingRangeClientCapabilities
+		want   []FoldingRange
+	}{
+		{name: "none", want: lines()},
+		{name: "sorted", ranges: lines(4, 6, 0, 2), want: lines(0, 2, 4, 6)},
+		{name: "nested", ranges: lines(1, 2, 0, 5), want: lines(0, 5, 1, 2)},
+		{name: "same start keeps the outermost", ranges: lines(0, 3, 0, 5), want: lines(0, 5)},
+		{name: "partial overlap is merged", ranges: lines(0, 4, 2, 6), want: lines(0, 6)},
+		{name: "sharing a line", ranges: lines(0, 5, 5, 8), want: lines(0, 4, 5, 8)},
+		{name: "else chain", ranges: lines(0, 2, 2, 4, 4, 6), want: lines(0, 1, 2, 3, 4, 6)},
+		{name: "nested ranges sharing a line", ranges: lines(0, 5, 2, 5, 5, 8), want: lines(0, 4, 2, 4, 5, 8)},
+		{name: "nothing left after sharing a line", ranges: lines(4, 5, 5, 8), want: lines(5, 8)},
+		{name: "inverted", ranges: lines(3, 1), want: lines()},
+		{name: "single lines kept", ranges: lines(1, 1), want: lines(1, 1)},
+		{
+			name:   "single lines dropped when folding lines only",
+			ranges: lines(1, 1, 2, 4),
+			caps:   &FoldingRangeClientCapabilities{LineFoldingOnly: &yes},
+			want:   lines(2, 4),
+		},
+		{name: "limit keeps the outermost", ranges: lines(0, 9, 1, 3, 5, 7, 10, 12), caps: limit(2), want: lines(0, 9, 10, 12)},
+		{name: "negative limit", ranges: lines(0, 9), caps: limit(-1), want: lines()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LimitFoldingRanges(tt.ranges, tt.caps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitFoldingRangesDropsCharacters(t *testing.T) {
+	yes := true
+	start, end := int64(4), int64(1)
+	ranges := []FoldingRange{{StartLine: 0, StartCharacter: &start, EndLine: 2, EndCharacter: &end}}
+	got := LimitFoldingRanges(ranges, &FoldingRangeClientCapabilities{LineFoldingOnly: &yes})
+	if want := lines(0, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	ranges = append(ranges, FoldingRange{StartLine: 2, EndLine: 4})
+	got = LimitFoldingRanges(ranges, nil)
+	if got[0].EndLine != 1 || got[0].EndCharacter != nil {
+		t.Errorf("kept the end character of a shortened range: %v", got)
+	}
+}
+
+func TestIndentationFoldingRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []FoldingRange
+	}{
+		{name: "empty", text: "", want: lines()},
+		{name: "flat", text: "a\nb\nc", want: lines()},
+		{name: "block", text: "a\n  b\n  c\nd", want: lines(0, 2)},
+		{name: "nested", text: "a\n  b\n    c\n  d\ne", want: lines(0, 3, 1, 2)},
+		{name: "if else", text: "if x\n  a\nelse\n  b\n", want: lines(0, 1, 2, 3)},
+		{name: "blank lines inside a block", text: "a\n  b\n\n  c\n\nd", want: lines(0, 3)},
+		{name: "tabs", text: "a\n\tb\n    c\nd", want: lines(0, 2)},
+		{name: "crlf", text: "a\r\n  b\r\n  c\r\nd", want: lines(0, 2)},
+		{name: "cr", text: "a\r  b\r    c\r  d\re", want: lines(0, 3, 1, 2)},
+		{name: "mixed line endings", text: "a\r  b\n\r\n  c\rd", want: lines(0, 3)},
+		{name: "unterminated block", text: "a\n  b", want: lines(0, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IndentationFoldingRanges(tt.text, 4)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].StartLine <= got[i-1].EndLine && got[i].EndLine > got[i-1].EndLine {
+					t.Errorf("ranges %v and %v overlap", got[i-1], got[i])
+				}
+			}
+		})
+	}
+}
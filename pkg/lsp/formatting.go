@@ -0,0 +1,236 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//FormattingOptions are value-object describing what options formatting should use
+type FormattingOptions struct {
+	//TabSize is the size of a tab in spaces
+	TabSize int64 `json:"tabSize"`
+	//InsertSpaces prefers spaces over tabs
+	InsertSpaces bool `json:"insertSpaces"`
+	//TrimTrailingWhitespace trims trailing whitespace on a line
+	TrimTrailingWhitespace *bool `json:"trimTrailingWhitespace,omitempty"`
+	//InsertFinalNewline inserts a newline character at the end of the file if one does not exist
+	InsertFinalNewline *bool `json:"insertFinalNewline,omitempty"`
+	//TrimFinalNewlines trims all newlines after the final newline at the end of the file
+	TrimFinalNewlines *bool `json:"trimFinalNewlines,omitempty"`
+	//Properties holds further formatting options, whose values are booleans, numbers or strings
+	Properties map[string]interface{} `json:"-"`
+}
+
+type formattingOptions FormattingOptions
+
+var formattingOptionNames = map[string]bool{
+	"tabSize":                true,
+	"insertSpaces":           true,
+	"trimTrailingWhitespace": true,
+	"insertFinalNewline":     true,
+	"trimFinalNewlines":      true,
+}
+
+//MarshalJSON encodes the formatting options, with any further properties alongside the well-known ones
+func (fo FormattingOptions) MarshalJSON() ([]byte, error) {
+	js, err := json.Marshal(formattingOptions(fo))
+	if err != nil || len(fo.Properties) == 0 {
+		return js, err
+	}
+	all := map[string]interface{}{}
+	if err := json.Unmarshal(js, &all); err != nil {
+		return nil, err
+	}
+	for name, value := range fo.Properties {
+		if !formattingOptionNames[name] {
+			all[name] = value
+		}
+	}
+	return json.Marshal(all)
+}
+
+//UnmarshalJSON decodes the formatting options, collecting properties other than the well-known ones in `Properties`
+func (fo *FormattingOptions) UnmarshalJSON(js []byte) error {
+	options := formattingOptions{}
+	if err := json.Unmarshal(js, &options); err != nil {
+		return err
+	}
+	all := map[string]interface{}{}
+	if err := json.Unmarshal(js, &all); err != nil {
+		return err
+	}
+	for name, value := range all {
+		if !formattingOptionNames[name] {
+			if options.Properties == nil {
+				options.Properties = map[string]interface{}{}
+			}
+			options.Properties[name] = value
+		}
+	}
+	*fo = FormattingOptions(options)
+	return nil
+}
+
+//DocumentFormattingParams are the parameters of a `textDocument/formatting` request
+type DocumentFormattingParams struct {
+	//The document to format
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//Options are the format options
+	Options FormattingOptions `json:"options"`
+}
+
+//DocumentRangeFormattingParams are the parameters of a `textDocument/rangeFormatting` request
+type DocumentRangeFormattingParams struct {
+	//The document to format
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//Range to format
+	Range code.Range `json:"range"`
+	//Options are the format options
+	Options FormattingOptions `json:"options"`
+}
+
+//DocumentOnTypeFormattingParams are the parameters of a `textDocument/onTypeFormatting` request
+type DocumentOnTypeFormattingParams struct {
+	//The document to format
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//Position at which this request was sent. This is the position after the typed character
+	Position code.Position `json:"position"`
+	//Ch is the character that has been typed
+	Ch string `json:"ch"`
+	//Options are the format options
+	Options FormattingOptions `json:"options"`
+}
+
+//DocumentFormattingOptions are the server capabilities for document formatting
+type DocumentFormattingOptions struct {
+	*WorkDoneProgressOptions
+}
+
+type documentFormattingUnion struct {
+	Boolean *bool
+	Options *DocumentFormattingOptions
+}
+
+func (du *documentFormattingUnion) MarshalJSON() ([]byte, error) {
+	if du.Boolean != nil {
+		return json.Marshal(*du.Boolean)
+	}
+	return json.Marshal(du.Options)
+}
+
+func (du *documentFormattingUnion) UnmarshalJSON(js []byte) error {
+	*du = documentFormattingUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		du.Boolean = &b
+		return nil
+	}
+	du.Options = &DocumentFormattingOptions{}
+	return json.Unmarshal(js, du.Options)
+}
+
+//DocumentRangeFormattingOptions are the server capabilities for document range formatting
+type DocumentRangeFormattingOptions struct {
+	*WorkDoneProgressOptions
+}
+
+type documentRangeFormattingUnion struct {
+	Boolean *bool
+	Options *DocumentRangeFormattingOptions
+}
+
+func (du *documentRangeFormattingUnion) MarshalJSON() ([]byte, error) {
+	if du.Boolean != nil {
+		return json.Marshal(*du.Boolean)
+	}
+	return json.Marshal(du.Options)
+}
+
+func (du *documentRangeFormattingUnion) UnmarshalJSON(js []byte) error {
+	*du = documentRangeFormattingUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		du.Boolean = &b
+		return nil
+	}
+	du.Options = &DocumentRangeFormattingOptions{}
+	return json.Unmarshal(js, du.Options)
+}
+
+//DocumentOnTypeFormattingOptions are the server capabilities for formatting while typing
+type DocumentOnTypeFormattingOptions struct {
+	//FirstTriggerCharacter is a character on which formatting should be triggered, like `}`
+	FirstTriggerCharacter string `json:"firstTriggerCharacter"`
+	//MoreTriggerCharacter are more trigger characters
+	MoreTriggerCharacter []string `json:"moreTriggerCharacter,omitempty"`
+}
+
+//DocumentFormattingProvider is implemented by embedding servers that format whole documents for `textDocument/formatting`
+type DocumentFormattingProvider interface {
+	FormatDocument(params *DocumentFormattingParams) ([]TextEdit, error)
+}
+
+//DocumentRangeFormattingProvider is implemented by embedding servers that format parts of documents for `textDocument/rangeFormatting`
+type DocumentRangeFormattingProvider interface {
+	FormatRange(params *DocumentRangeFormattingParams) ([]TextEdit, error)
+}
+
+//DocumentOnTypeFormattingProvider is implemented by embedding servers that format documents while the user types, for `textDocument/onTypeFormatting`
+type DocumentOnTypeFormattingProvider interface {
+	//OnTypeFormattingTriggerCharacters returns the characters that trigger formatting
+	OnTypeFormattingTriggerCharacters() (first string, more []string)
+	FormatOnType(params *DocumentOnTypeFormattingParams) ([]TextEdit, error)
+}
+
+func (s *DefaultServer) formatting(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DocumentFormattingProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := DocumentFormattingParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	edits, err := provider.FormatDocument(&params)
+	s.reply(req, edits, err)
+}
+
+func (s *DefaultServer) rangeFormatting(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DocumentRangeFormattingProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := DocumentRangeFormattingParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	edits, err := provider.FormatRange(&params)
+	s.reply(req, edits, err)
+}
+
+func (s *DefaultServer) onTypeFormatting(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(DocumentOnTypeFormattingProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := DocumentOnTypeFormattingParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	edits, err := provider.FormatOnType(&params)
+	s.reply(req, edits, err)
+}
+
+//FormattingEdits computes the edits turning the `current` text of a document into the `formatted` output of a formatter,
+//so that an external formatter can be used without replacing the whole document
+func FormattingEdits(current, formatted string) []TextEdit {
+	return ComputeEdits(current, formatted)
+}
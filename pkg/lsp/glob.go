@@ -0,0 +1,206 @@
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//Glob is a compiled LSP glob pattern. The pattern syntax is:
+// * `*` matches zero or more characters in a path segment
+// * `?` matches one character in a path segment
+// * `**` matches any number of path segments, including none
+// * `{}` groups alternatives, e.g. `**/*.{ts,js}`
+// * `[]` declares a range of characters to match in a path segment, e.g. `example.[0-9]`
+// * `[!...]` negates a range of characters, e.g. `example.[!0-9]`
+type Glob struct {
+	pattern string
+	re      *regexp.Regexp
+	//segments are the globs of the path segments of the pattern, with nil standing for `**`.
+	//They are nil if the pattern has alternatives spanning segments, such as `{src/a,lib}`
+	segments []*Glob
+}
+
+//CompileGlob compiles a glob pattern, which matches paths separated by `/`
+func CompileGlob(pattern string, ignoreCase bool) (*Glob, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if ignoreCase {
+		sb.WriteString("(?i)")
+	}
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '{':
+			depth++
+			sb.WriteString("(?:")
+		case '}':
+			if depth == 0 {
+				return nil, fmt.Errorf("unbalanced '}' in glob pattern %q", pattern)
+			}
+			depth--
+			sb.WriteString(")")
+		case ',':
+			if depth > 0 {
+				sb.WriteString("|")
+			} else {
+				sb.WriteString(",")
+			}
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated '[' in glob pattern %q", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			sb.WriteString("[")
+			if strings.HasPrefix(class, "!") {
+				sb.WriteString("^/")
+				class = class[1:]
+			}
+			for _, r := range class {
+				if r == '-' {
+					sb.WriteRune(r)
+				} else {
+					sb.WriteString(regexp.QuoteMeta(string(r)))
+				}
+			}
+			sb.WriteString("]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced '{' in glob pattern %q", pattern)
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %v", pattern, err)
+	}
+	g := &Glob{pattern: pattern, re: re}
+	switch {
+	case alternativesSpanSegments(pattern):
+	case !strings.Contains(pattern, "/") && pattern != "**" && strings.Contains(pattern, "**"):
+		//a `**` within a segment, as in `a**`, matches across segments
+	case !strings.Contains(pattern, "/"):
+		if pattern == "**" {
+			g.segments = []*Glob{nil}
+		} else {
+			g.segments = []*Glob{g}
+		}
+	default:
+		for _, segment := range strings.Split(pattern, "/") {
+			sg, err := CompileGlob(segment, ignoreCase)
+			if err != nil || sg.segments == nil {
+				//e.g. a range including `/`, which cannot be matched segment by segment
+				g.segments = nil
+				break
+			}
+			g.segments = append(g.segments, sg.segments...)
+		}
+	}
+	return g, nil
+}
+
+//alternativesSpanSegments reports whether a `/` appears inside `{}` in the pattern
+func alternativesSpanSegments(pattern string) bool {
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '/':
+			if depth > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//EscapeGlob escapes the glob metacharacters of `path`, so that it matches itself when used as part of a glob pattern
+func EscapeGlob(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		switch r {
+		case '*', '?', '[', '{', '}':
+			sb.WriteString("[" + string(r) + "]")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+//Match reports whether `path` matches the glob
+func (g *Glob) Match(path string) bool {
+	return g.re.MatchString(path)
+}
+
+//MayMatchUnder reports whether paths inside the directory `dir` may match the glob, so that directories it returns false for
+//need not be searched. An empty `dir` stands for the directory relative patterns are matched in. It errs on the side of true when it cannot tell
+func (g *Glob) MayMatchUnder(dir string) bool {
+	if g.segments == nil {
+		return true
+	}
+	//states holds the indices of the pattern segments the next path segment can be matched against
+	states := map[int]bool{}
+	var add func(i int)
+	add = func(i int) {
+		if states[i] {
+			return
+		}
+		states[i] = true
+		if i < len(g.segments) && g.segments[i] == nil {
+			//`**` matches no segment too
+			add(i + 1)
+		}
+	}
+	add(0)
+	segments := []string{}
+	if dir != "" {
+		segments = strings.Split(strings.TrimSuffix(dir, "/"), "/")
+	}
+	for _, segment := range segments {
+		current := states
+		states = map[int]bool{}
+		for i := range current {
+			switch {
+			case i >= len(g.segments):
+			case g.segments[i] == nil:
+				add(i)
+			case g.segments[i].Match(segment):
+				add(i + 1)
+			}
+		}
+	}
+	for i := range states {
+		//paths inside the directory have at least one more segment to match
+		if i < len(g.segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Glob) String() string {
+	return g.pattern
+}
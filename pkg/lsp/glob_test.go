@@ -0,0 +1,104 @@
+package lsp
+
+import "testing"
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		ignoreCase bool
+		matches    []string
+		misses     []string
+	}{
+		{pattern: "*.go", matches: []string{"main.go", ".go"}, misses: []string{"src/main.go", "main.go.txt"}},
+		{pattern: "?.go", matches: []string{"a.go"}, misses: []string{"ab.go", "/.go"}},
+		{pattern: "**/*.go", matches: []string{"main.go", "src/main.go", "/a/b/c/main.go"}, misses: []string{"main.gox"}},
+		{pattern: "src/**", matches: []string{"src/", "src/a", "src/a/b.go"}, misses: []string{"lib/src/a"}},
+		{pattern: "a/**/b", matches: []string{"a/b", "a/x/b", "a/x/y/b"}, misses: []string{"a/xb", "ab"}},
+		{pattern: "**/*.{ts,js}", matches: []string{"a.ts", "x/a.js"}, misses: []string{"a.tsx", "a.{ts,js}"}},
+		{pattern: "{src,lib}/{a,b}.go", matches: []string{"src/a.go", "lib/b.go"}, misses: []string{"src/c.go", "test/a.go"}},
+		{pattern: "a,b", matches: []string{"a,b"}, misses: []string{"a", "b"}},
+		{pattern: "example.[0-9]", matches: []string{"example.0", "example.9"}, misses: []string{"example.a", "example.10"}},
+		{pattern: "example.[!0-9]", matches: []string{"example.a"}, misses: []string{"example.0", "example./"}},
+		{pattern: "[.]git", matches: []string{".git"}, misses: []string{"xgit"}},
+		{pattern: "*.GO", ignoreCase: true, matches: []string{"main.go", "MAIN.Go"}, misses: []string{"main.gox"}},
+		{pattern: "*.GO", matches: []string{"main.GO"}, misses: []string{"main.go"}},
+		{pattern: "(a)+.txt", matches: []string{"(a)+.txt"}, misses: []string{"aa.txt"}},
+		{pattern: "héllo/*", matches: []string{"héllo/x"}, misses: []string{"hello/x"}},
+	}
+	for _, tt := range tests {
+		g, err := CompileGlob(tt.pattern, tt.ignoreCase)
+		if err != nil {
+			t.Errorf("CompileGlob(%q) failed: %v", tt.pattern, err)
+			continue
+		}
+		for _, path := range tt.matches {
+			if !g.Match(path) {
+				t.Errorf("%q does not match %q", tt.pattern, path)
+			}
+		}
+		for _, path := range tt.misses {
+			if g.Match(path) {
+				t.Errorf("%q matches %q", tt.pattern, path)
+			}
+		}
+	}
+}
+
+func TestCompileGlobErrors(t *testing.T) {
+	for _, pattern := range []string{"{a,b", "a}", "[a-z", "[z-a]"} {
+		if _, err := CompileGlob(pattern, false); err == nil {
+			t.Errorf("CompileGlob(%q) succeeded, want an error", pattern)
+		}
+	}
+}
+
+func TestEscapeGlob(t *testing.T) {
+	for _, path := range []string{"/ws/plain", "/ws/a[1]", "/ws/{x,y}", "/ws/what?", "/ws/*star*", "/ws/ünïcode"} {
+		escaped := EscapeGlob(path)
+		g, err := CompileGlob(escaped+"/**/*.go", false)
+		if err != nil {
+			t.Errorf("cannot compile escaped %q: %v", escaped, err)
+			continue
+		}
+		if !g.Match(path + "/a/main.go") {
+			t.Errorf("%q does not match files under %q", g, path)
+		}
+		if path != "/ws/plain" && g.Match("/ws/plain/a/main.go") {
+			t.Errorf("%q matches files outside %q", g, path)
+		}
+	}
+}
+
+func TestGlobMayMatchUnder(t *testing.T) {
+	tests := []struct {
+		pattern string
+		under   []string
+		outside []string
+	}{
+		{pattern: "**/*.go", under: []string{"", "src", "a/b/c"}},
+		{pattern: "*.go", under: []string{""}, outside: []string{"src"}},
+		{pattern: "src/**/*.go", under: []string{"src", "src/a/b"}, outside: []string{"lib", ".git", "lib/src"}},
+		{pattern: "src/*.go", under: []string{"src"}, outside: []string{"src/a"}},
+		{pattern: "{src,lib}/*.go", under: []string{"src", "lib"}, outside: []string{"test"}},
+		{pattern: "{src/a,lib}/*.go", under: []string{"src", "test"}},
+		{pattern: "a**/x.go", under: []string{"ab/c"}},
+		{pattern: "[st]rc/**", under: []string{"src", "trc/x"}, outside: []string{"arc"}},
+		{pattern: "/ws/**/*.go", under: []string{"/ws", "/ws/a"}, outside: []string{"/other"}},
+	}
+	for _, tt := range tests {
+		g, err := CompileGlob(tt.pattern, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, dir := range tt.under {
+			if !g.MayMatchUnder(dir) {
+				t.Errorf("%q cannot match under %q", tt.pattern, dir)
+			}
+		}
+		for _, dir := range tt.outside {
+			if g.MayMatchUnder(dir) {
+				t.Errorf("%q may match under %q", tt.pattern, dir)
+			}
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//InlayHintKind is the kind of an inlay hint
+type InlayHintKind int
+
+//The inlay hint kinds
+const (
+	//InlayHintKindType is for type annotations
+	InlayHintKindType InlayHintKind = 1
+	//InlayHintKindParameter is for parameter names
+	InlayHintKindParameter InlayHintKind = 2
+)
+
+//InlayHintParams are the parameters of a `textDocument/inlayHint` request
+type InlayHintParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//Range is the visible document range for which inlay hints should be computed
+	Range code.Range `json:"range"`
+}
+
+//InlayHint is additional information, such as a type or parameter name, rendered inline with the source text
+type InlayHint struct {
+	//Position of this hint
+	Position code.Position `json:"position"`
+	//Label of this hint, either a string or label parts
+	Label InlayHintLabel `json:"label"`
+	//Kind of this hint, if any
+	Kind *InlayHintKind `json:"kind,omitempty"`
+	//TextEdits are performed when accepting this inlay hint
+	TextEdits []TextEdit `json:"textEdits,omitempty"`
+	//Tooltip shown when hovering over this hint
+	Tooltip *InlayHintTooltip `json:"tooltip,omitempty"`
+	//PaddingLeft renders padding before the hint
+	PaddingLeft *bool `json:"paddingLeft,omitempty"`
+	//PaddingRight renders padding after the hint
+	PaddingRight *bool `json:"paddingRight,omitempty"`
+	//Data is preserved between a `textDocument/inlayHint` and an `inlayHint/resolve` request
+	Data *json.RawMessage `json:"data,omitempty"`
+}
+
+//NewInlayHint creates an inlay hint with a string label
+func NewInlayHint(position code.Position, label string, kind InlayHintKind) InlayHint {
+	return InlayHint{
+		Position: position,
+		Label:    InlayHintLabel{Value: label},
+		Kind:     &kind,
+	}
+}
+
+//Pad sets whether the hint is rendered with padding before and after it
+func (h *InlayHint) Pad(left, right bool) *InlayHint {
+	h.PaddingLeft = &left
+	h.PaddingRight = &right
+	return h
+}
+
+//InlayHintLabel is the label of an inlay hint: a string `Value`, or the `Parts` when there are any
+type InlayHintLabel struct {
+	Value string
+	Parts []InlayHintLabelPart
+}
+
+func (l InlayHintLabel) MarshalJSON() ([]byte, error) {
+	if l.Parts != nil {
+		return json.Marshal(l.Parts)
+	}
+	return json.Marshal(l.Value)
+}
+
+func (l *InlayHintLabel) UnmarshalJSON(js []byte) error {
+	*l = InlayHintLabel{}
+	if err := json.Unmarshal(js, &l.Value); err == nil {
+		return nil
+	}
+	return json.Unmarshal(js, &l.Parts)
+}
+
+//InlayHintTooltip is the tooltip of an inlay hint or label part: a plain string `Value`, or `Markup` when it is set
+type InlayHintTooltip struct {
+	Value  string
+	Markup *MarkupContent
+}
+
+func (t InlayHintTooltip) MarshalJSON() ([]byte, error) {
+	if t.Markup != nil {
+		return json.Marshal(t.Markup)
+	}
+	return json.Marshal(t.Value)
+}
+
+func (t *InlayHintTooltip) UnmarshalJSON(js []byte) error {
+	*t = InlayHintTooltip{}
+	if err := json.Unmarshal(js, &t.Value); err == nil {
+		return nil
+	}
+	t.Markup = &MarkupContent{}
+	return json.Unmarshal(js, t.Markup)
+}
+
+//InlayHintLabelPart is a part of an inlay hint label, which can be interacted with individually
+type InlayHintLabelPart struct {
+	//Value of the label part
+	Value string `json:"value"`
+	//Tooltip shown when hovering over this label part
+	Tooltip *InlayHintTooltip `json:"tooltip,omitempty"`
+	//Location of the source code element this part refers to, used for hovers, go to definition and the like
+	Location *code.Location `json:"location,omitempty"`
+	//Command run when this label part is clicked
+	Command *Command `json:"command,omitempty"`
+}
+
+//InlayHintOptions are the server capabilities for inlay hints
+type InlayHintOptions struct {
+	*WorkDoneProgressOptions
+	//ResolveProvider indicates that the server provides additional information for inlay hints on `inlayHint/resolve`
+	ResolveProvider *bool `json:"resolveProvider,omitempty"`
+}
+
+//InlayHintRegistrationOptions are the registration options for inlay hints
+type InlayHintRegistrationOptions struct {
+	*InlayHintOptions
+	*TextDocumentRegistrationOptions
+	*StaticRegistrationOptions
+}
+
+type inlayHintUnion struct {
+	Boolean             *bool
+	Options             *InlayHintOptions
+	RegistrationOptions *InlayHintRegistrationOptions
+}
+
+func (iu *inlayHintUnion) MarshalJSON() ([]byte, error) {
+	if iu.Boolean != nil {
+		return json.Marshal(*iu.Boolean)
+	}
+	if iu.Options != nil {
+		return json.Marshal(*iu.Options)
+	}
+	return json.Marshal(iu.RegistrationOptions)
+}
+
+func (iu *inlayHintUnion) UnmarshalJSON(js []byte) error {
+	*iu = inlayHintUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		iu.Boolean = &b
+		return nil
+	}
+	iu.RegistrationOptions = &InlayHintRegistrationOptions{}
+	return json.Unmarshal(js, iu.RegistrationOptions)
+}
+
+//InlayHintProvider is implemented by embedding servers that compute inlay hints for `textDocument/inlayHint`.
+//The tooltips, locations and commands of label parts are only sent to clients that can resolve them, and can be filled in by an `InlayHintResolver`
+type InlayHintProvider interface {
+	InlayHints(params *InlayHintParams) ([]InlayHint, error)
+}
+
+//InlayHintResolver is optionally implemented by an `InlayHintProvider` to fill in the details of an inlay hint lazily on `inlayHint/resolve`
+type InlayHintResolver interface {
+	ResolveInlayHint(hint *InlayHint) (*InlayHint, error)
+}
+
+func (s *DefaultServer) inlayHintCapability() *inlayHintUnion {
+	_, resolve := s.provider().(InlayHintResolver)
+	if !resolve {
+		supported := true
+		return &inlayHintUnion{Boolean: &supported}
+	}
+	return &inlayHintUnion{Options: &InlayHintOptions{ResolveProvider: &resolve}}
+}
+
+func (s *DefaultServer) inlayHint(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(InlayHintProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := InlayHintParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	hints, err := provider.InlayHints(&params)
+	if err == nil {
+		for i := range hints {
+			s.adaptInlayHint(&hints[i])
+		}
+	}
+	s.reply(req, hints, err)
+}
+
+func (s *DefaultServer) resolveInlayHint(req *jsonrpc2.Request) {
+	resolver, ok := s.provider().(InlayHintResolver)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	hint := InlayHint{}
+	if err := decodeParams(req, &hint); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	resolved, err := resolver.ResolveInlayHint(&hint)
+	s.reply(req, resolved, err)
+}
+
+//adaptInlayHint drops the label part properties the client did not declare it can resolve from the hints of `textDocument/inlayHint`.
+//Hints returned by `inlayHint/resolve` are sent as is, since resolving is how the client obtains those properties
+func (s *DefaultServer) adaptInlayHint(hint *InlayHint) {
+	tooltip := s.canResolveInlayHint("label.tooltip")
+	location := s.canResolveInlayHint("label.location")
+	command := s.canResolveInlayHint("label.command")
+	for i := range hint.Label.Parts {
+		part := &hint.Label.Parts[i]
+		if !tooltip {
+			part.Tooltip = nil
+		}
+		if !location {
+			part.Location = nil
+		}
+		if !command {
+			part.Command = nil
+		}
+	}
+}
+
+//canResolveInlayHint reports whether the client can resolve the inlay hint `property` lazily
+func (s *DefaultServer) canResolveInlayHint(property string) bool {
+	tdc := s.ClientCapabilities().TextDocumentCapabilities
+	if tdc == nil || tdc.InlayHint == nil || tdc.InlayHint.ResolveSupport == nil {
+		return false
+	}
+	for _, p := range tdc.InlayHint.ResolveSupport.Properties {
+		if p == property {
+			return true
+		}
+	}
+	return false
+}
+
+//RefreshInlayHints sends `workspace/inlayHint/refresh` to ask the client to re-request all inlay hints, if the client supports it.
+//Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) RefreshInlayHints(ctx context.Context) error {
+	var supported *bool
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc != nil && wc.InlayHint != nil {
+		supported = wc.InlayHint.RefreshSupport
+	}
+	return s.refresh(ctx, "workspace/inlayHint/refresh", supported)
+}
@@ -0,0 +1,91 @@
+package lsp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestInlayHintJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		hint InlayHint
+		js   string
+	}{
+		{
+			name: "string label and tooltip",
+			hint: InlayHint{Position: rng(0, 1, 0, 1).Start, Label: InlayHintLabel{Value: ": int"}, Tooltip: &InlayHintTooltip{Value: "inferred"}},
+			js:   `{"position":{"line":0,"character":1},"label":": int","tooltip":"inferred"}`,
+		},
+		{
+			name: "label parts and markup tooltip",
+			hint: InlayHint{
+				Position: rng(2, 3, 2, 3).Start,
+				Label:    InlayHintLabel{Parts: []InlayHintLabelPart{{Value: "x", Tooltip: &InlayHintTooltip{Markup: &MarkupContent{Kind: MarkupKindMarkdown, Value: "`x`"}}}}},
+			},
+			js: `{"position":{"line":2,"character":3},"label":[{"value":"x","tooltip":{"kind":"markdown","value":"` + "`x`" + `"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js, err := json.Marshal(tt.hint)
+			if err != nil || string(js) != tt.js {
+				t.Errorf("got %s, %v, want %s", js, err, tt.js)
+			}
+			decoded := InlayHint{}
+			decode(t, js, &decoded)
+			if !reflect.DeepEqual(decoded, tt.hint) {
+				t.Errorf("decoded %+v, want %+v", decoded, tt.hint)
+			}
+		})
+	}
+}
+
+//inlayHintServer returns a hint whose label part has a tooltip, location and command, both initially and when resolved
+type inlayHintServer struct {
+	*testServer
+}
+
+func (s *inlayHintServer) hint() InlayHint {
+	return InlayHint{
+		Label: InlayHintLabel{Parts: []InlayHintLabelPart{{
+			Value:   "x",
+			Tooltip: &InlayHintTooltip{Value: "tip"},
+			Command: &Command{Title: "go", Command: "go"},
+		}}},
+		Tooltip: &InlayHintTooltip{Value: "hint"},
+	}
+}
+
+func (s *inlayHintServer) InlayHints(params *InlayHintParams) ([]InlayHint, error) {
+	return []InlayHint{s.hint()}, nil
+}
+
+func (s *inlayHintServer) ResolveInlayHint(hint *InlayHint) (*InlayHint, error) {
+	resolved := s.hint()
+	return &resolved, nil
+}
+
+func TestInlayHintResolveKeepsProperties(t *testing.T) {
+	s := &inlayHintServer{newTestServer()}
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{"textDocument":{"inlayHint":{"resolveSupport":{"properties":["label.tooltip"]}}}}}`)
+
+	c.request(1, "textDocument/inlayHint", map[string]interface{}{
+		"textDocument": map[string]string{"uri": "file:///a.dsl"},
+		"range":        rng(0, 0, 1, 0),
+	})
+	hints := []InlayHint{}
+	decode(t, c.next().Result, &hints)
+	part := hints[0].Label.Parts[0]
+	if part.Tooltip == nil || part.Command != nil || hints[0].Tooltip == nil {
+		t.Errorf("got %+v, want the label part command dropped for a client that cannot resolve it", hints[0])
+	}
+
+	c.request(2, "inlayHint/resolve", hints[0])
+	resolved := InlayHint{}
+	decode(t, c.next().Result, &resolved)
+	if part := resolved.Label.Parts[0]; part.Tooltip == nil || part.Command == nil {
+		t.Errorf("got %+v, want the resolved hint sent as is", resolved)
+	}
+}
@@ -0,0 +1,182 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//InlineValueParams are the parameters of a `textDocument/inlineValue` request
+type InlineValueParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//Range is the document range for which inline values should be computed
+	Range code.Range `json:"range"`
+	//Context holds additional information about the debugger state when the inline values were requested
+	Context InlineValueContext `json:"context"`
+}
+
+//InlineValueContext describes the debugger state of an inline values request
+type InlineValueContext struct {
+	//FrameID is the stack frame (as a DAP id) where the execution has stopped
+	FrameID int64 `json:"frameId"`
+	//StoppedLocation is the document range where execution has stopped, typically the end position of the range denotes the line where the inline values are shown
+	StoppedLocation code.Range `json:"stoppedLocation"`
+}
+
+//InlineValueText provides inline value information as text
+type InlineValueText struct {
+	//Range of the document for which the inline value applies
+	Range code.Range `json:"range"`
+	//Text of the inline value
+	Text string `json:"text"`
+}
+
+//InlineValueVariableLookup provides inline value information through a variable lookup by the client.
+//If only a range is specified, the variable name is extracted from the underlying document
+type InlineValueVariableLookup struct {
+	//Range of the document for which the inline value applies, which is used to extract the variable name
+	Range code.Range `json:"range"`
+	//VariableName is the name of the variable to look up, if different from the text of the range
+	VariableName *string `json:"variableName,omitempty"`
+	//CaseSensitiveLookup indicates how to perform the lookup
+	CaseSensitiveLookup bool `json:"caseSensitiveLookup"`
+}
+
+//InlineValueEvaluatableExpression provides inline value information through an expression evaluated by the client.
+//If only a range is specified, the expression is extracted from the underlying document
+type InlineValueEvaluatableExpression struct {
+	//Range of the document for which the inline value applies, which is used to extract the expression
+	Range code.Range `json:"range"`
+	//Expression to evaluate, if different from the text of the range
+	Expression *string `json:"expression,omitempty"`
+}
+
+//InlineValue is one of the inline value variants. Exactly one of its fields should be set
+type InlineValue struct {
+	Text                  *InlineValueText
+	VariableLookup        *InlineValueVariableLookup
+	EvaluatableExpression *InlineValueEvaluatableExpression
+}
+
+//NewInlineValueText creates an inline value showing `text` for `rng`
+func NewInlineValueText(rng code.Range, text string) InlineValue {
+	return InlineValue{Text: &InlineValueText{Range: rng, Text: text}}
+}
+
+//NewInlineValueVariableLookup creates an inline value showing the value of the variable in `rng`, or of `variableName` if it is not empty
+func NewInlineValueVariableLookup(rng code.Range, variableName string, caseSensitive bool) InlineValue {
+	lookup := InlineValueVariableLookup{Range: rng, CaseSensitiveLookup: caseSensitive}
+	if variableName != "" {
+		lookup.VariableName = &variableName
+	}
+	return InlineValue{VariableLookup: &lookup}
+}
+
+//NewInlineValueEvaluatableExpression creates an inline value showing the value of the expression in `rng`, or of `expression` if it is not empty
+func NewInlineValueEvaluatableExpression(rng code.Range, expression string) InlineValue {
+	evaluatable := InlineValueEvaluatableExpression{Range: rng}
+	if expression != "" {
+		evaluatable.Expression = &expression
+	}
+	return InlineValue{EvaluatableExpression: &evaluatable}
+}
+
+func (iv InlineValue) MarshalJSON() ([]byte, error) {
+	if iv.Text != nil {
+		return json.Marshal(iv.Text)
+	}
+	if iv.VariableLookup != nil {
+		return json.Marshal(iv.VariableLookup)
+	}
+	return json.Marshal(iv.EvaluatableExpression)
+}
+
+func (iv *InlineValue) UnmarshalJSON(js []byte) error {
+	*iv = InlineValue{}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(js, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["text"]; ok {
+		iv.Text = &InlineValueText{}
+		return json.Unmarshal(js, iv.Text)
+	}
+	if _, ok := fields["caseSensitiveLookup"]; ok {
+		iv.VariableLookup = &InlineValueVariableLookup{}
+		return json.Unmarshal(js, iv.VariableLookup)
+	}
+	iv.EvaluatableExpression = &InlineValueEvaluatableExpression{}
+	return json.Unmarshal(js, iv.EvaluatableExpression)
+}
+
+//InlineValueOptions are the server capabilities for inline values
+type InlineValueOptions struct {
+	*WorkDoneProgressOptions
+}
+
+//InlineValueRegistrationOptions are the registration options for inline values
+type InlineValueRegistrationOptions struct {
+	*InlineValueOptions
+	*TextDocumentRegistrationOptions
+	*StaticRegistrationOptions
+}
+
+type inlineValueUnion struct {
+	Boolean             *bool
+	Options             *InlineValueOptions
+	RegistrationOptions *InlineValueRegistrationOptions
+}
+
+func (iu *inlineValueUnion) MarshalJSON() ([]byte, error) {
+	if iu.Boolean != nil {
+		return json.Marshal(*iu.Boolean)
+	}
+	if iu.Options != nil {
+		return json.Marshal(*iu.Options)
+	}
+	return json.Marshal(iu.RegistrationOptions)
+}
+
+func (iu *inlineValueUnion) UnmarshalJSON(js []byte) error {
+	*iu = inlineValueUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		iu.Boolean = &b
+		return nil
+	}
+	iu.RegistrationOptions = &InlineValueRegistrationOptions{}
+	return json.Unmarshal(js, iu.RegistrationOptions)
+}
+
+//InlineValueProvider is implemented by embedding servers that compute inline values shown by the client while debugging
+type InlineValueProvider interface {
+	InlineValues(params *InlineValueParams) ([]InlineValue, error)
+}
+
+func (s *DefaultServer) inlineValue(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(InlineValueProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := InlineValueParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	values, err := provider.InlineValues(&params)
+	s.reply(req, values, err)
+}
+
+//RefreshInlineValues sends `workspace/inlineValue/refresh` to ask the client to re-request all inline values, if the client supports it.
+//Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) RefreshInlineValues(ctx context.Context) error {
+	var supported *bool
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc != nil && wc.InlineValue != nil {
+		supported = wc.InlineValue.RefreshSupport
+	}
+	return s.refresh(ctx, "workspace/inlineValue/refresh", supported)
+}
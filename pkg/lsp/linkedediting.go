@@ -0,0 +1,141 @@
+package lsp
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//LinkedEditingRangeParams are the parameters of a `textDocument/linkedEditingRange` request
+type LinkedEditingRangeParams struct {
+	TextDocumentPositionParams
+}
+
+//LinkedEditingRanges are ranges that have the same content and are edited simultaneously, such as the names of an opening and a closing tag
+type LinkedEditingRanges struct {
+	//Ranges that can be edited together. They must have identical length and contain no white space
+	Ranges []code.Range `json:"ranges"`
+	//WordPattern describes valid contents for the ranges. If missing the client's default word pattern is used
+	WordPattern *string `json:"wordPattern,omitempty"`
+}
+
+//LinkedEditingRangeOptions are the server capabilities for linked editing ranges
+type LinkedEditingRangeOptions struct {
+	*WorkDoneProgressOptions
+}
+
+//LinkedEditingRangeRegistrationOptions are the registration options for linked editing ranges
+type LinkedEditingRangeRegistrationOptions struct {
+	*TextDocumentRegistrationOptions
+	*LinkedEditingRangeOptions
+	*StaticRegistrationOptions
+}
+
+type linkedEditingRangeUnion struct {
+	Boolean             *bool
+	Options             *LinkedEditingRangeOptions
+	RegistrationOptions *LinkedEditingRangeRegistrationOptions
+}
+
+func (lu *linkedEditingRangeUnion) MarshalJSON() ([]byte, error) {
+	if lu.Boolean != nil {
+		return json.Marshal(*lu.Boolean)
+	}
+	if lu.Options != nil {
+		return json.Marshal(*lu.Options)
+	}
+	return json.Marshal(lu.RegistrationOptions)
+}
+
+func (lu *linkedEditingRangeUnion) UnmarshalJSON(js []byte) error {
+	*lu = linkedEditingRangeUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		lu.Boolean = &b
+		return nil
+	}
+	lu.RegistrationOptions = &LinkedEditingRangeRegistrationOptions{}
+	return json.Unmarshal(js, lu.RegistrationOptions)
+}
+
+//LinkedEditingRangeProvider is implemented by embedding servers that compute linked editing ranges. A nil result means there are no linked ranges at the position
+type LinkedEditingRangeProvider interface {
+	LinkedEditingRanges(params *LinkedEditingRangeParams) (*LinkedEditingRanges, error)
+}
+
+func (s *DefaultServer) linkedEditingRange(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(LinkedEditingRangeProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := LinkedEditingRangeParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	ranges, err := provider.LinkedEditingRanges(&params)
+	s.reply(req, ranges, err)
+}
+
+//TagNamePattern is the word pattern of the tag names matched by `TagLinkedEditingRanges`
+const TagNamePattern = `[A-Za-z_][\w:.-]*`
+
+var tagPattern = regexp.MustCompile(`<(/?)(` + TagNamePattern + `)(?:[^<>"']|"[^"]*"|'[^']*')*?(/?)>`)
+
+//TagLinkedEditingRanges returns the names of an opening tag and its matching closing tag in XML-like `text`, if the position is on either name.
+//Self-closing tags have no linked ranges, and unmatched tags are skipped when pairing opening with closing tags
+func TagLinkedEditingRanges(text string, position code.Position) *LinkedEditingRanges {
+	offset, err := code.OffsetAt(text, position)
+	if err != nil {
+		return nil
+	}
+	type tag struct {
+		start, end int
+		closing    bool
+	}
+	tags := []tag{}
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(text, -1) {
+		if m[7] > m[6] {
+			continue
+		}
+		tags = append(tags, tag{start: m[4], end: m[5], closing: m[3] > m[2]})
+	}
+	pairs := make(map[int]int)
+	open := []int{}
+	for i, t := range tags {
+		if !t.closing {
+			open = append(open, i)
+			continue
+		}
+		for j := len(open) - 1; j >= 0; j-- {
+			if text[tags[open[j]].start:tags[open[j]].end] == text[t.start:t.end] {
+				pairs[open[j]] = i
+				pairs[i] = open[j]
+				open = open[:j]
+				break
+			}
+		}
+	}
+	for i, t := range tags {
+		other, ok := pairs[i]
+		if !ok || offset < t.start || offset > t.end {
+			continue
+		}
+		first, second := t, tags[other]
+		if second.start < first.start {
+			first, second = second, first
+		}
+		pattern := TagNamePattern
+		return &LinkedEditingRanges{
+			Ranges: []code.Range{
+				{Start: code.PositionAt(text, first.start), End: code.PositionAt(text, first.end)},
+				{Start: code.PositionAt(text, second.start), End: code.PositionAt(text, second.end)},
+			},
+			WordPattern: &pattern,
+		}
+	}
+	return nil
+}
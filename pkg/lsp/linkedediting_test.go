@@ -0,0 +1,53 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+func TestTagLinkedEditingRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		position code.Position
+		want     []code.Range
+	}{
+		{name: "outer opening tag", text: "<a><a>x</a></a>", position: code.Position{Character: 1}, want: []code.Range{rng(0, 1, 0, 2), rng(0, 13, 0, 14)}},
+		{name: "inner opening tag", text: "<a><a>x</a></a>", position: code.Position{Character: 4}, want: []code.Range{rng(0, 4, 0, 5), rng(0, 9, 0, 10)}},
+		{name: "outer closing tag", text: "<a><a>x</a></a>", position: code.Position{Character: 13}, want: []code.Range{rng(0, 1, 0, 2), rng(0, 13, 0, 14)}},
+		{name: "end of inner closing tag name", text: "<a><a>x</a></a>", position: code.Position{Character: 10}, want: []code.Range{rng(0, 4, 0, 5), rng(0, 9, 0, 10)}},
+		{name: "self-closing tag", text: "<a><br/></a>", position: code.Position{Character: 4}},
+		{name: "around a self-closing tag", text: "<a><br/></a>", position: code.Position{Character: 1}, want: []code.Range{rng(0, 1, 0, 2), rng(0, 10, 0, 11)}},
+		{name: "quoted attribute value", text: `<a title="x>y" alt='<b>'><b/></a>`, position: code.Position{Character: 2}, want: []code.Range{rng(0, 1, 0, 2), rng(0, 31, 0, 32)}},
+		{name: "unmatched opening tag", text: "<a><b></a>", position: code.Position{Character: 4}},
+		{name: "around an unmatched opening tag", text: "<a><b></a>", position: code.Position{Character: 1}, want: []code.Range{rng(0, 1, 0, 2), rng(0, 8, 0, 9)}},
+		{name: "unmatched closing tag", text: "<a></c></a>", position: code.Position{Character: 5}},
+		{name: "around an unmatched closing tag", text: "<a></c></a>", position: code.Position{Character: 9}, want: []code.Range{rng(0, 1, 0, 2), rng(0, 9, 0, 10)}},
+		{name: "multiple lines", text: "<root>\n  <item>\n  </item>\n</root>", position: code.Position{Line: 2, Character: 5}, want: []code.Range{rng(1, 3, 1, 7), rng(2, 4, 2, 8)}},
+		{name: "namespaced name", text: "<x:item.v-1></x:item.v-1>", position: code.Position{Character: 3}, want: []code.Range{rng(0, 1, 0, 11), rng(0, 14, 0, 24)}},
+		{name: "outside a tag name", text: "<a>x</a>", position: code.Position{Character: 3}},
+		{name: "past the end of the text", text: "<a></a>", position: code.Position{Line: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TagLinkedEditingRanges(tt.text, tt.position)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("got %v, want no linked ranges", got.Ranges)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("got no linked ranges, want %v", tt.want)
+			}
+			if !reflect.DeepEqual(got.Ranges, tt.want) {
+				t.Errorf("got %v, want %v", got.Ranges, tt.want)
+			}
+			if got.WordPattern == nil || *got.WordPattern != TagNamePattern {
+				t.Errorf("got word pattern %v, want %q", got.WordPattern, TagNamePattern)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//UniquenessLevel describes the scope in which a moniker identifier is unique
+type UniquenessLevel string
+
+//The uniqueness levels, from the narrowest to the widest scope
+const (
+	//UniquenessLevelDocument means the moniker is only unique inside a document
+	UniquenessLevelDocument UniquenessLevel = "document"
+	//UniquenessLevelProject means the moniker is unique inside a project for which a dump got created
+	UniquenessLevelProject UniquenessLevel = "project"
+	//UniquenessLevelGroup means the moniker is unique inside the group to which a project belongs
+	UniquenessLevelGroup UniquenessLevel = "group"
+	//UniquenessLevelScheme means the moniker is unique inside the moniker scheme
+	UniquenessLevelScheme UniquenessLevel = "scheme"
+	//UniquenessLevelGlobal means the moniker is globally unique
+	UniquenessLevelGlobal UniquenessLevel = "global"
+)
+
+//MonikerKind describes whether a symbol is imported into or exported from a project, or local to it
+type MonikerKind string
+
+//The moniker kinds
+const (
+	//MonikerKindImport is for symbols imported into a project
+	MonikerKindImport MonikerKind = "import"
+	//MonikerKindExport is for symbols exported from a project
+	MonikerKindExport MonikerKind = "export"
+	//MonikerKindLocal is for symbols local to a project, such as a local variable of a function or a private class
+	MonikerKindLocal MonikerKind = "local"
+)
+
+//MonikerParams are the parameters of a `textDocument/moniker` request
+type MonikerParams struct {
+	TextDocumentPositionParams
+}
+
+//Moniker is a stable identifier of a symbol, which can be matched across repositories and with the monikers emitted by LSIF or SCIP indexers
+type Moniker struct {
+	//Scheme of the moniker, for example the name of the indexer or the package manager
+	Scheme string `json:"scheme"`
+	//Identifier of the moniker. The value is opaque in LSIF however schema owners are allowed to define the structure if they want
+	Identifier string `json:"identifier"`
+	//Unique is the scope in which the moniker is unique
+	Unique UniquenessLevel `json:"unique"`
+	//Kind of the moniker, if known
+	Kind *MonikerKind `json:"kind,omitempty"`
+}
+
+//NewMoniker creates a moniker of the given kind
+func NewMoniker(scheme, identifier string, unique UniquenessLevel, kind MonikerKind) Moniker {
+	return Moniker{
+		Scheme:     scheme,
+		Identifier: identifier,
+		Unique:     unique,
+		Kind:       &kind,
+	}
+}
+
+//MonikerOptions are the server capabilities for monikers
+type MonikerOptions struct {
+	*WorkDoneProgressOptions
+}
+
+//MonikerRegistrationOptions are the registration options for monikers
+type MonikerRegistrationOptions struct {
+	*TextDocumentRegistrationOptions
+	*MonikerOptions
+}
+
+type monikerUnion struct {
+	Boolean             *bool
+	Options             *MonikerOptions
+	RegistrationOptions *MonikerRegistrationOptions
+}
+
+func (mu *monikerUnion) MarshalJSON() ([]byte, error) {
+	if mu.Boolean != nil {
+		return json.Marshal(*mu.Boolean)
+	}
+	if mu.Options != nil {
+		return json.Marshal(*mu.Options)
+	}
+	return json.Marshal(mu.RegistrationOptions)
+}
+
+func (mu *monikerUnion) UnmarshalJSON(js []byte) error {
+	*mu = monikerUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		mu.Boolean = &b
+		return nil
+	}
+	mu.RegistrationOptions = &MonikerRegistrationOptions{}
+	return json.Unmarshal(js, mu.RegistrationOptions)
+}
+
+//MonikerProvider is implemented by embedding servers that provide the monikers of the symbol at a position
+type MonikerProvider interface {
+	Monikers(params *MonikerParams) ([]Moniker, error)
+}
+
+func (s *DefaultServer) moniker(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(MonikerProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := MonikerParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	monikers, err := provider.Monikers(&params)
+	s.reply(req, monikers, err)
+}
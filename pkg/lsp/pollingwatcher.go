@@ -0,0 +1,168 @@
+package lsp
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+//EnablePollingFileWatcher makes the server watch the workspace folders itself, by scanning them every `interval`, when the client
+//cannot register file watchers dynamically. The changes are passed to the `WatchedFilesProvider` exactly as the events of `workspace/didChangeWatchedFiles`
+//would be, but from the polling goroutine. It must be called before `Start`
+func (s *DefaultServer) EnablePollingFileWatcher(interval time.Duration) {
+	s.pollInterval = interval
+}
+
+//fileStamp is what the polling watcher remembers of a file to detect changes
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+//pollingWatcher scans directory trees periodically and reports the files created, changed or deleted since the previous scan.
+//The roots are fetched before each scan, so they follow the workspace folders: the files of a root are first reported on
+//the scan after the one that found the root, and no events are reported for the files of roots that are no longer scanned.
+//Directories `search` returns false for, such as `.git` when no watcher is interested in it, are not scanned
+type pollingWatcher struct {
+	roots    func() []string
+	interval time.Duration
+	search   func(dir string) bool
+	//files holds the files found by the previous scan of each root
+	files map[string]map[string]fileStamp
+	emit  func([]FileEvent)
+	stop  chan struct{}
+}
+
+func newPollingWatcher(roots func() []string, interval time.Duration, search func(dir string) bool, emit func([]FileEvent)) *pollingWatcher {
+	return &pollingWatcher{
+		roots:    roots,
+		interval: interval,
+		search:   search,
+		files:    make(map[string]map[string]fileStamp),
+		emit:     emit,
+		stop:     make(chan struct{}),
+	}
+}
+
+//run records the current state of the roots, then reports changes every interval until the watcher is stopped
+func (w *pollingWatcher) run() {
+	w.poll()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if events := w.poll(); len(events) > 0 {
+				w.emit(events)
+			}
+		}
+	}
+}
+
+//poll scans the current roots and returns the changes to the roots that were already scanned, sorted by path
+func (w *pollingWatcher) poll() []FileEvent {
+	events := []FileEvent{}
+	files := make(map[string]map[string]fileStamp)
+	for _, root := range w.roots() {
+		if _, done := files[root]; done {
+			continue
+		}
+		files[root] = w.scan(root)
+		if previous, ok := w.files[root]; ok {
+			events = append(events, diffScans(previous, files[root])...)
+		}
+	}
+	w.files = files
+	sort.SliceStable(events, func(i, j int) bool { return events[i].URI < events[j].URI })
+	return events
+}
+
+func (w *pollingWatcher) close() {
+	close(w.stop)
+}
+
+func (w *pollingWatcher) scan(root string) map[string]fileStamp {
+	files := make(map[string]fileStamp)
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if w.search != nil && !w.search(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files
+}
+
+//diffScans returns the events turning the `previous` scan into the `current` one, sorted by path
+func diffScans(previous, current map[string]fileStamp) []FileEvent {
+	paths := []string{}
+	for path := range current {
+		paths = append(paths, path)
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	events := []FileEvent{}
+	for _, path := range paths {
+		before, existed := previous[path]
+		after, exists := current[path]
+		switch {
+		case !existed:
+			events = append(events, FileEvent{URI: code.URIFromPath(path), Type: FileChangeTypeCreated})
+		case !exists:
+			events = append(events, FileEvent{URI: code.URIFromPath(path), Type: FileChangeTypeDeleted})
+		case before != after:
+			events = append(events, FileEvent{URI: code.URIFromPath(path), Type: FileChangeTypeChanged})
+		}
+	}
+	return events
+}
+
+//workspaceRoots returns the file system paths of the workspace folders
+func (s *DefaultServer) workspaceRoots() []string {
+	roots := []string{}
+	for _, folder := range s.Workspace().Folders() {
+		if path, err := folder.URI.Filename(); err == nil {
+			roots = append(roots, path)
+		}
+	}
+	return roots
+}
+
+//watchesUnder reports whether a watcher may be interested in files inside the directory at `dir`
+func (s *DefaultServer) watchesUnder(dir string) bool {
+	u, err := url.Parse(string(code.URIFromPath(dir)))
+	if err != nil {
+		return true
+	}
+	for _, cw := range s.fileWatchers {
+		if cw.mayMatchUnder(u.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+//pollFiles starts the polling watcher if it is enabled and the client cannot watch files. It scans the workspace folders as they change
+func (s *DefaultServer) pollFiles() {
+	if s.pollInterval <= 0 || len(s.fileWatchers) == 0 || s.canWatchFiles() {
+		return
+	}
+	s.poller = newPollingWatcher(s.workspaceRoots, s.pollInterval, s.watchesUnder, s.dispatchFileEvents)
+	go s.poller.run()
+}
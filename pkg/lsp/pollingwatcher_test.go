@@ -0,0 +1,119 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+func TestDiffScans(t *testing.T) {
+	t0 := time.Unix(1000, 0)
+	t1 := time.Unix(2000, 0)
+	previous := map[string]fileStamp{
+		"/ws/same":     {modTime: t0, size: 1},
+		"/ws/touched":  {modTime: t0, size: 1},
+		"/ws/resized":  {modTime: t0, size: 1},
+		"/ws/deleted":  {modTime: t0, size: 1},
+		"/ws/replaced": {modTime: t0, size: 1},
+	}
+	current := map[string]fileStamp{
+		"/ws/same":     {modTime: t0, size: 1},
+		"/ws/touched":  {modTime: t1, size: 1},
+		"/ws/resized":  {modTime: t0, size: 2},
+		"/ws/created":  {modTime: t1, size: 1},
+		"/ws/replaced": {modTime: t1, size: 3},
+	}
+	want := []FileEvent{
+		{URI: code.URIFromPath("/ws/created"), Type: FileChangeTypeCreated},
+		{URI: code.URIFromPath("/ws/deleted"), Type: FileChangeTypeDeleted},
+		{URI: code.URIFromPath("/ws/replaced"), Type: FileChangeTypeChanged},
+		{URI: code.URIFromPath("/ws/resized"), Type: FileChangeTypeChanged},
+		{URI: code.URIFromPath("/ws/touched"), Type: FileChangeTypeChanged},
+	}
+	if got := diffScans(previous, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := diffScans(current, current); len(got) != 0 {
+		t.Errorf("got %v for identical scans", got)
+	}
+	if got := diffScans(map[string]fileStamp{}, map[string]fileStamp{}); len(got) != 0 {
+		t.Errorf("got %v for empty scans", got)
+	}
+}
+
+func TestScanPrunesUnwatchedDirectories(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"src/a.dsl", "src/nested/b.dsl", ".git/objects/c.dsl", "node_modules/x/d.dsl", "top.dsl"} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	folder := WorkspaceFolder{URI: code.URIFromPath(root), Name: "root"}
+	s := &DefaultServer{}
+	s.fileWatchers = compileFileSystemWatchers([]FileSystemWatcher{
+		{GlobPattern: NewRelativeGlobPattern(folder, "src/**/*.dsl")},
+		{GlobPattern: NewRelativeGlobPattern(folder, "*.dsl")},
+	})
+	searched := []string{}
+	w := newPollingWatcher(func() []string { return []string{root} }, time.Hour, func(dir string) bool {
+		rel, _ := filepath.Rel(root, dir)
+		searched = append(searched, filepath.ToSlash(rel))
+		return s.watchesUnder(dir)
+	}, nil)
+	scanned := []string{}
+	for path := range w.scan(root) {
+		rel, _ := filepath.Rel(root, path)
+		scanned = append(scanned, filepath.ToSlash(rel))
+	}
+	sort.Strings(scanned)
+	if want := []string{"src/a.dsl", "src/nested/b.dsl", "top.dsl"}; !reflect.DeepEqual(scanned, want) {
+		t.Errorf("scanned %q, want %q", scanned, want)
+	}
+	sort.Strings(searched)
+	if want := []string{".", ".git", "node_modules", "src", "src/nested"}; !reflect.DeepEqual(searched, want) {
+		t.Errorf("searched %q, want %q", searched, want)
+	}
+}
+
+func TestPollingWatcherFollowsRoots(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	write := func(path string) {
+		if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(first, "a.dsl"))
+	write(filepath.Join(second, "b.dsl"))
+	workspace := NewWorkspace(WorkspaceFolder{URI: code.URIFromPath(first), Name: "first"})
+	s := &DefaultServer{workspace: workspace}
+	s.workspaceOnce.Do(func() {})
+	w := newPollingWatcher(s.workspaceRoots, time.Hour, nil, nil)
+	if events := w.poll(); len(events) != 0 {
+		t.Fatalf("got %v on the first scan", events)
+	}
+
+	workspace.Add(WorkspaceFolder{URI: code.URIFromPath(second), Name: "second"})
+	if events := w.poll(); len(events) != 0 {
+		t.Errorf("got %v for the existing files of an added folder", events)
+	}
+	write(filepath.Join(second, "c.dsl"))
+	want := []FileEvent{{URI: code.URIFromPath(filepath.Join(second, "c.dsl")), Type: FileChangeTypeCreated}}
+	if events := w.poll(); !reflect.DeepEqual(events, want) {
+		t.Errorf("got %v, want %v", events, want)
+	}
+
+	workspace.SetFolders([]WorkspaceFolder{{URI: code.URIFromPath(second), Name: "second"}})
+	write(filepath.Join(first, "d.dsl"))
+	if events := w.poll(); len(events) != 0 {
+		t.Errorf("got %v after removing a folder", events)
+	}
+}
@@ -0,0 +1,61 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+)
+
+//Registration is a capability the server registers dynamically with `client/registerCapability`
+type Registration struct {
+	//ID used to register the request. The id can be used to deregister the request again
+	ID string `json:"id"`
+	//Method the registration is for
+	Method string `json:"method"`
+	//RegisterOptions are the options necessary for the registration
+	RegisterOptions interface{} `json:"registerOptions,omitempty"`
+}
+
+//RegistrationParams are the parameters of a `client/registerCapability` request
+type RegistrationParams struct {
+	Registrations []Registration `json:"registrations"`
+}
+
+//Unregistration is a capability the server unregisters with `client/unregisterCapability`
+type Unregistration struct {
+	//ID used to unregister the request or notification, usually the id provided in the registration
+	ID string `json:"id"`
+	//Method to unregister
+	Method string `json:"method"`
+}
+
+//UnregistrationParams are the parameters of a `client/unregisterCapability` request
+type UnregistrationParams struct {
+	//Unregisterations is misspelled in the protocol, and kept as is for backward compatibility
+	Unregisterations []Unregistration `json:"unregisterations"`
+}
+
+//RegisterCapability dynamically registers `method` with `options` with the client, and returns the registration's id.
+//Callers should first check that the client supports dynamic registration for the feature.
+//Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) RegisterCapability(ctx context.Context, method string, options interface{}) (string, error) {
+	s.callMutex.Lock()
+	s.lastRegistrationID++
+	id := fmt.Sprintf("%s#%d", method, s.lastRegistrationID)
+	s.callMutex.Unlock()
+	params := RegistrationParams{
+		Registrations: []Registration{{ID: id, Method: method, RegisterOptions: options}},
+	}
+	if err := s.Call(ctx, "client/registerCapability", params, nil); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+//UnregisterCapability unregisters a capability registered for `method` with `RegisterCapability`.
+//Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) UnregisterCapability(ctx context.Context, id, method string) error {
+	params := UnregistrationParams{
+		Unregisterations: []Unregistration{{ID: id, Method: method}},
+	}
+	return s.Call(ctx, "client/unregisterCapability", params, nil)
+}
@@ -0,0 +1,226 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//RenameParams are the parameters of a `textDocument/rename` request
+type RenameParams struct {
+	TextDocumentPositionParams
+	//NewName of the symbol. If the given name is not valid the request must return an error with an appropriate message set
+	NewName string `json:"newName"`
+}
+
+//PrepareRenameParams are the parameters of a `textDocument/prepareRename` request
+type PrepareRenameParams struct {
+	TextDocumentPositionParams
+}
+
+//PrepareRenameResult is the result of a `textDocument/prepareRename` request, in one of three forms:
+//the range of the string to rename, that range with a placeholder for the new name, or the client's default behavior
+type PrepareRenameResult struct {
+	//Range of the string to rename
+	Range code.Range
+	//Placeholder text of the string content to be renamed, if not empty
+	Placeholder string
+	//DefaultBehavior asks the client to determine the range to rename itself, if it declared `prepareSupportDefaultBehavior`
+	DefaultBehavior bool
+}
+
+type prepareRenamePlaceholder struct {
+	Range       code.Range `json:"range"`
+	Placeholder string     `json:"placeholder"`
+}
+
+type prepareRenameDefaultBehavior struct {
+	DefaultBehavior bool `json:"defaultBehavior"`
+}
+
+//MarshalJSON encodes the form of the result that is set
+func (pr *PrepareRenameResult) MarshalJSON() ([]byte, error) {
+	if pr.DefaultBehavior {
+		return json.Marshal(prepareRenameDefaultBehavior{DefaultBehavior: true})
+	}
+	if pr.Placeholder != "" {
+		return json.Marshal(prepareRenamePlaceholder{Range: pr.Range, Placeholder: pr.Placeholder})
+	}
+	return json.Marshal(pr.Range)
+}
+
+//UnmarshalJSON decodes any of the forms of the result
+func (pr *PrepareRenameResult) UnmarshalJSON(js []byte) error {
+	*pr = PrepareRenameResult{}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(js, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["defaultBehavior"]; ok {
+		result := prepareRenameDefaultBehavior{}
+		err := json.Unmarshal(js, &result)
+		pr.DefaultBehavior = result.DefaultBehavior
+		return err
+	}
+	if _, ok := fields["placeholder"]; ok {
+		result := prepareRenamePlaceholder{}
+		err := json.Unmarshal(js, &result)
+		pr.Range, pr.Placeholder = result.Range, result.Placeholder
+		return err
+	}
+	return json.Unmarshal(js, &pr.Range)
+}
+
+//RenameOptions are the server capabilities for renaming
+type RenameOptions struct {
+	*WorkDoneProgressOptions
+	//PrepareProvider indicates that renames should be checked and tested before being executed
+	PrepareProvider *bool `json:"prepareProvider,omitempty"`
+}
+
+type renameUnion struct {
+	Boolean *bool
+	Options *RenameOptions
+}
+
+func (ru *renameUnion) MarshalJSON() ([]byte, error) {
+	if ru.Boolean != nil {
+		return json.Marshal(*ru.Boolean)
+	}
+	return json.Marshal(ru.Options)
+}
+
+func (ru *renameUnion) UnmarshalJSON(js []byte) error {
+	*ru = renameUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		ru.Boolean = &b
+		return nil
+	}
+	ru.Options = &RenameOptions{}
+	return json.Unmarshal(js, ru.Options)
+}
+
+//RenameProvider is implemented by embedding servers that rename symbols for `textDocument/rename`.
+//Edits that should be confirmed by the user can be annotated with a `ChangeAnnotation` whose `NeedsConfirmation` is set,
+//using the builder returned by `DefaultServer.NewWorkspaceEditBuilder`. If the client does not declare that it honors annotations
+//for renames, it cannot ask the user for confirmation: the changes needing it are left out of the edit, and the other annotations removed
+type RenameProvider interface {
+	Rename(params *RenameParams) (*WorkspaceEdit, error)
+}
+
+//PrepareRenameProvider is optionally implemented by a `RenameProvider` to validate a rename on `textDocument/prepareRename`.
+//It returns a nil result if no rename is possible at the given position
+type PrepareRenameProvider interface {
+	PrepareRename(params *PrepareRenameParams) (*PrepareRenameResult, error)
+}
+
+func (s *DefaultServer) renameCapability() *renameUnion {
+	_, prepare := s.provider().(PrepareRenameProvider)
+	tdc := s.ClientCapabilities().TextDocumentCapabilities
+	if !prepare || tdc == nil || tdc.Rename == nil || tdc.Rename.PrepareSupport == nil || !*tdc.Rename.PrepareSupport {
+		//rename options may only be specified if the client states that it supports `prepareSupport`
+		supported := true
+		return &renameUnion{Boolean: &supported}
+	}
+	return &renameUnion{Options: &RenameOptions{PrepareProvider: &prepare}}
+}
+
+func (s *DefaultServer) rename(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(RenameProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := RenameParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	edit, err := provider.Rename(&params)
+	if err == nil && edit != nil && !s.honorsRenameAnnotations() {
+		edit = withoutAnnotations(edit)
+	}
+	s.reply(req, edit, err)
+}
+
+func (s *DefaultServer) prepareRename(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(PrepareRenameProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := PrepareRenameParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	result, err := provider.PrepareRename(&params)
+	if err == nil && result != nil && result.DefaultBehavior && !s.supportsPrepareRenameDefaultBehavior() {
+		//the client cannot determine the range itself, so renaming is reported as not possible here
+		result = nil
+	}
+	s.reply(req, result, err)
+}
+
+//honorsRenameAnnotations tells whether the client presents the change annotations of a rename edit to the user,
+//for instance to confirm the edits that need it
+func (s *DefaultServer) honorsRenameAnnotations() bool {
+	tdc := s.ClientCapabilities().TextDocumentCapabilities
+	return tdc != nil && tdc.Rename != nil && tdc.Rename.HonorsChangeAnnotations != nil && *tdc.Rename.HonorsChangeAnnotations
+}
+
+//withoutAnnotations returns a copy of `edit` without change annotations, leaving out the changes whose annotation needs confirmation
+func withoutAnnotations(edit *WorkspaceEdit) *WorkspaceEdit {
+	needsConfirmation := func(id *ChangeAnnotationIdentifier) bool {
+		if id == nil {
+			return false
+		}
+		annotation, ok := edit.ChangeAnnotations[*id]
+		return ok && annotation.NeedsConfirmation != nil && *annotation.NeedsConfirmation
+	}
+	confirmed := func(edits []TextEdit) []TextEdit {
+		kept := []TextEdit{}
+		for _, e := range edits {
+			if !needsConfirmation(e.AnnotationID) {
+				kept = append(kept, e)
+			}
+		}
+		return kept
+	}
+	stripped := WorkspaceEdit{}
+	if edit.DocumentChanges != nil {
+		changes := []DocumentChange{}
+		for _, change := range edit.DocumentChanges {
+			switch {
+			case change.TextDocumentEdit != nil:
+				tde := *change.TextDocumentEdit
+				if tde.Edits = confirmed(tde.Edits); len(tde.Edits) == 0 && len(change.TextDocumentEdit.Edits) > 0 {
+					continue
+				}
+				change = DocumentChange{TextDocumentEdit: &tde}
+			case change.CreateFile != nil && needsConfirmation(change.CreateFile.AnnotationID),
+				change.RenameFile != nil && needsConfirmation(change.RenameFile.AnnotationID),
+				change.DeleteFile != nil && needsConfirmation(change.DeleteFile.AnnotationID):
+				continue
+			}
+			changes = append(changes, change)
+		}
+		stripped.DocumentChanges = copyDocumentChanges(changes, false)
+	}
+	if edit.Changes != nil {
+		stripped.Changes = make(map[code.DocumentURI][]TextEdit, len(edit.Changes))
+		for uri, edits := range edit.Changes {
+			if kept := confirmed(edits); len(kept) > 0 || len(edits) == 0 {
+				stripped.Changes[uri] = copyTextEdits(kept, false)
+			}
+		}
+	}
+	return &stripped
+}
+
+func (s *DefaultServer) supportsPrepareRenameDefaultBehavior() bool {
+	tdc := s.ClientCapabilities().TextDocumentCapabilities
+	return tdc != nil && tdc.Rename != nil && tdc.Rename.PrepareSupportDefaultBehavior != nil
+}
@@ -11,7 +11,7 @@ type InitializeParams struct {
 	ProcessID             *int64             `json:"processId,omitempty"`
 	ClientInfo            *ClientInfo        `json:"clientInfo,omitempty"`
 	RootPath              *string            `json:"rootPath,omitempty"`
-	RootURI               *code.DocumentURI  `json:"documentUri,omitempty"`
+	RootURI               *code.DocumentURI  `json:"rootUri,omitempty"`
 	InitializationOptions *json.RawMessage   `json:"initializationOptions,omitempty"`
 	Capabilities          ClientCapabilities `json:"capabilities"`
 	Trace                 *string            `json:"trace,omitempty"`
@@ -28,17 +28,25 @@ type ClientInfo struct {
 type ClientCapabilities struct {
 	WorkspaceCapabilities    *WorkspaceCapabilities          `json:"workspace,omitempty"`
 	TextDocumentCapabilities *TextDocumentClientCapabilities `json:"textDocument,omitempty"`
+	WindowCapabilities       *WindowClientCapabilities       `json:"window,omitempty"`
 	Experimental             *json.RawMessage                `json:"experimental,omitempty"`
 }
 
 //WorkspaceCapabilities are workspace-specific client capabilities.
 type WorkspaceCapabilities struct {
-	ApplyEdit              *bool                                     `json:"applyEdit,omitempty"`
-	WorkspaceEdit          *WorkspaceEditClientCapabilities          `json:"workspaceEdit,omitempty"`
-	DidChangeConfiguration *DidChangeConfigurationClientCapabilities `json:"didChangeConfiguration,omitempty"`
-	DidChangeWatchedFiles  *DidChangeWatchedFilesClientCapabilities  `json:"didChangeWatchedFiles,omitempty"`
-	Symbol                 *WorkspaceSymbolClientCapabilities        `json:"symbol,omitempty"`
-	ExecuteCommand         *ExecuteCommandClientCapabilities         `json:"executeCommand,omitempty"`
+	ApplyEdit              *bool                                      `json:"applyEdit,omitempty"`
+	WorkspaceFolders       *bool                                      `json:"workspaceFolders,omitempty"`
+	Configuration          *bool                                      `json:"configuration,omitempty"`
+	WorkspaceEdit          *WorkspaceEditClientCapabilities           `json:"workspaceEdit,omitempty"`
+	DidChangeConfiguration *DidChangeConfigurationClientCapabilities  `json:"didChangeConfiguration,omitempty"`
+	DidChangeWatchedFiles  *DidChangeWatchedFilesClientCapabilities   `json:"didChangeWatchedFiles,omitempty"`
+	Symbol                 *WorkspaceSymbolClientCapabilities         `json:"symbol,omitempty"`
+	ExecuteCommand         *ExecuteCommandClientCapabilities          `json:"executeCommand,omitempty"`
+	CodeLens               *CodeLensWorkspaceClientCapabilities       `json:"codeLens,omitempty"`
+	SemanticTokens         *SemanticTokensWorkspaceClientCapabilities `json:"semanticTokens,omitempty"`
+	InlayHint              *InlayHintWorkspaceClientCapabilities      `json:"inlayHint,omitempty"`
+	InlineValue            *InlineValueWorkspaceClientCapabilities    `json:"inlineValue,omitempty"`
+	FileOperations         *FileOperationClientCapabilities           `json:"fileOperations,omitempty"`
 }
 
 //TextDocumentClientCapabilities Text document specific client capabilities
@@ -64,6 +72,30 @@ type TextDocumentClientCapabilities struct {
 	Rename             *RenameClientCapabilities                   `json:"rename,omitempty"`
 	PublishDiagnostics *PublishDiagnosticsClientCapabilities       `json:"publishDiagnostics,omitempty"`
 	FoldingRange       *FoldingRangeClientCapabilities             `json:"foldingRange,omitempty"`
+	SelectionRange     *SelectionRangeClientCapabilities           `json:"selectionRange,omitempty"`
+	CallHierarchy      *CallHierarchyClientCapabilities            `json:"callHierarchy,omitempty"`
+	TypeHierarchy      *TypeHierarchyClientCapabilities            `json:"typeHierarchy,omitempty"`
+	SemanticTokens     *SemanticTokensClientCapabilities           `json:"semanticTokens,omitempty"`
+	InlayHint          *InlayHintClientCapabilities                `json:"inlayHint,omitempty"`
+	InlineValue        *InlineValueClientCapabilities              `json:"inlineValue,omitempty"`
+	LinkedEditingRange *LinkedEditingRangeClientCapabilities       `json:"linkedEditingRange,omitempty"`
+	Moniker            *MonikerClientCapabilities                  `json:"moniker,omitempty"`
+}
+
+//WindowClientCapabilities are window-specific client capabilities.
+type WindowClientCapabilities struct {
+	WorkDoneProgress *bool                                 `json:"workDoneProgress,omitempty"`
+	ShowMessage      *ShowMessageRequestClientCapabilities `json:"showMessage,omitempty"`
+}
+
+//ShowMessageRequestClientCapabilities describes client capabilities specific to the `window/showMessageRequest` request.
+type ShowMessageRequestClientCapabilities struct {
+	MessageActionItem *messageActionItemSupport `json:"messageActionItem,omitempty"`
+}
+
+type messageActionItemSupport struct {
+	//AdditionalPropertiesSupport indicates whether the client supports additional attributes which are preserved and sent back to the server
+	AdditionalPropertiesSupport *bool `json:"additionalPropertiesSupport,omitempty"`
 }
 
 //WorkspaceFolder a workspace folder
@@ -74,9 +106,16 @@ type WorkspaceFolder struct {
 
 //WorkspaceEditClientCapabilities Capabilities specific to `WorkspaceEdit`s
 type WorkspaceEditClientCapabilities struct {
-	DocumentChanges    *bool                   `json:"DocumentChanges,omitempty"`
-	ResourceOperations []resourceOperationKind `json:"resourceOperations,omitempty"`
-	FailureHandling    *failureHandlingKind    `json:"failureHandling,omitempty"`
+	DocumentChanges         *bool                    `json:"documentChanges,omitempty"`
+	ResourceOperations      []ResourceOperationKind  `json:"resourceOperations,omitempty"`
+	FailureHandling         *FailureHandlingKind     `json:"failureHandling,omitempty"`
+	NormalizesLineEndings   *bool                    `json:"normalizesLineEndings,omitempty"`
+	ChangeAnnotationSupport *changeAnnotationSupport `json:"changeAnnotationSupport,omitempty"`
+}
+
+type changeAnnotationSupport struct {
+	//GroupsOnLabel indicates whether the client groups edits with equal labels into tree nodes, for instance all edits labelled with "Changes in Strings" would be a tree node
+	GroupsOnLabel *bool `json:"groupsOnLabel,omitempty"`
 }
 
 //DidChangeConfigurationClientCapabilities is a notification sent from the client to the server to signal the change of configuration settings
@@ -86,7 +125,8 @@ type DidChangeConfigurationClientCapabilities struct {
 
 //DidChangeWatchedFilesClientCapabilities is the watched files notification sent from the client to the server when the client detects changes to files watched by the language client
 type DidChangeWatchedFilesClientCapabilities struct {
-	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+	DynamicRegistration    *bool `json:"dynamicRegistration,omitempty"`
+	RelativePatternSupport *bool `json:"relativePatternSupport,omitempty"`
 }
 
 //ExecuteCommandClientCapabilities are capabilities specific to the `workspace/executeCommand` request
@@ -101,7 +141,7 @@ type WorkspaceSymbolClientCapabilities struct {
 }
 
 type symbolKindValues struct {
-	ValueSet []symbolKind `json:"valueSet,omitempty"`
+	ValueSet []SymbolKind `json:"valueSet,omitempty"`
 }
 
 //TextDocumentSyncClientCapabilities client capabilities for syncing text documents ;-)
@@ -134,13 +174,9 @@ type completionItem struct {
 }
 
 type markupKind string
-type codeActionKind string
-type symbolKind int
 type completionItemTag int
 type diagnosticTag int
 type completionItemKind int
-type resourceOperationKind string
-type failureHandlingKind string
 
 //DiagnosticRelatedInformation Represents a related message and source code location for a diagnostic. This should be
 // used to point to code locations that cause or are related to a diagnostics, e.g when duplicating
@@ -215,6 +251,9 @@ type CodeActionClientCapabilities struct {
 	DynamicRegistration      *bool                     `json:"dynamicRegistration,omitempty"`
 	CodeActionLiteralSupport *codeActionLiteralSupport `json:"codeActionLiteralSupport,omitempty"`
 	IsPreferredSupport       *bool                     `json:"isPreferredSupport,omitempty"`
+	DisabledSupport          *bool                     `json:"disabledSupport,omitempty"`
+	DataSupport              *bool                     `json:"dataSupport,omitempty"`
+	ResolveSupport           *codeActionResolveSupport `json:"resolveSupport,omitempty"`
 }
 
 type codeActionLiteralSupport struct {
@@ -222,7 +261,12 @@ type codeActionLiteralSupport struct {
 }
 
 type codeAction struct {
-	ValueSet []codeActionKind `json:"valueSet"`
+	ValueSet []CodeActionKind `json:"valueSet"`
+}
+
+type codeActionResolveSupport struct {
+	//Properties that a client can resolve lazily
+	Properties []string `json:"properties"`
 }
 
 //CodeLensClientCapabilities describes client capabilities specific to the `textDocument/codeLens`.
@@ -230,6 +274,37 @@ type CodeLensClientCapabilities struct {
 	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
 }
 
+//CodeLensWorkspaceClientCapabilities describes workspace client capabilities specific to code lenses
+type CodeLensWorkspaceClientCapabilities struct {
+	RefreshSupport *bool `json:"refreshSupport,omitempty"`
+}
+
+//SemanticTokensWorkspaceClientCapabilities describes workspace client capabilities specific to semantic tokens
+type SemanticTokensWorkspaceClientCapabilities struct {
+	RefreshSupport *bool `json:"refreshSupport,omitempty"`
+}
+
+//InlayHintWorkspaceClientCapabilities describes workspace client capabilities specific to inlay hints
+type InlayHintWorkspaceClientCapabilities struct {
+	RefreshSupport *bool `json:"refreshSupport,omitempty"`
+}
+
+//InlineValueWorkspaceClientCapabilities describes workspace client capabilities specific to inline values
+type InlineValueWorkspaceClientCapabilities struct {
+	RefreshSupport *bool `json:"refreshSupport,omitempty"`
+}
+
+//FileOperationClientCapabilities describes the file operation requests and notifications the client supports
+type FileOperationClientCapabilities struct {
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+	DidCreate           *bool `json:"didCreate,omitempty"`
+	WillCreate          *bool `json:"willCreate,omitempty"`
+	DidRename           *bool `json:"didRename,omitempty"`
+	WillRename          *bool `json:"willRename,omitempty"`
+	DidDelete           *bool `json:"didDelete,omitempty"`
+	WillDelete          *bool `json:"willDelete,omitempty"`
+}
+
 //DocumentLinkClientCapabilities describes client capabilities specific to the `textDocument/documentLink`.
 type DocumentLinkClientCapabilities struct {
 	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
@@ -258,8 +333,10 @@ type DocumentOnTypeFormattingClientCapabilities struct {
 
 //RenameClientCapabilities describes client capabilities specific to the `textDocument/rename`.
 type RenameClientCapabilities struct {
-	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
-	PrepareSupport      *bool `json:"prepareSupport,omitempty"`
+	DynamicRegistration           *bool  `json:"dynamicRegistration,omitempty"`
+	PrepareSupport                *bool  `json:"prepareSupport,omitempty"`
+	PrepareSupportDefaultBehavior *int64 `json:"prepareSupportDefaultBehavior,omitempty"`
+	HonorsChangeAnnotations       *bool  `json:"honorsChangeAnnotations,omitempty"`
 }
 
 //PublishDiagnosticsClientCapabilities describes client capabilities specific to the `textDocument/publishDiagnostics`.
@@ -275,6 +352,65 @@ type diagnosticTagSupport struct {
 //FoldingRangeClientCapabilities describes client capabilities specific to `textDocument/foldingRange requests`.
 type FoldingRangeClientCapabilities struct {
 	DynamicRegistration *bool  `json:"dynamicRegistration,omitempty"`
-	RangeLimit          *int64 `json:"RangeLimit,omitempty"`
+	RangeLimit          *int64 `json:"rangeLimit,omitempty"`
 	LineFoldingOnly     *bool  `json:"lineFoldingOnly,omitempty"`
 }
+
+//SelectionRangeClientCapabilities describes client capabilities specific to `textDocument/selectionRange` requests.
+type SelectionRangeClientCapabilities struct {
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+}
+
+//CallHierarchyClientCapabilities describes client capabilities specific to the call hierarchy requests.
+type CallHierarchyClientCapabilities struct {
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+}
+
+//TypeHierarchyClientCapabilities describes client capabilities specific to the type hierarchy requests.
+type TypeHierarchyClientCapabilities struct {
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+}
+
+//SemanticTokensClientCapabilities describes client capabilities specific to the semantic tokens requests.
+type SemanticTokensClientCapabilities struct {
+	DynamicRegistration     *bool                  `json:"dynamicRegistration,omitempty"`
+	Requests                semanticTokensRequests `json:"requests"`
+	TokenTypes              []string               `json:"tokenTypes"`
+	TokenModifiers          []string               `json:"tokenModifiers"`
+	Formats                 []string               `json:"formats"`
+	OverlappingTokenSupport *bool                  `json:"overlappingTokenSupport,omitempty"`
+	MultilineTokenSupport   *bool                  `json:"multilineTokenSupport,omitempty"`
+	ServerCancelSupport     *bool                  `json:"serverCancelSupport,omitempty"`
+	AugmentsSyntaxTokens    *bool                  `json:"augmentsSyntaxTokens,omitempty"`
+}
+
+type semanticTokensRequests struct {
+	Range *semanticTokensRequestUnion `json:"range,omitempty"`
+	Full  *semanticTokensRequestUnion `json:"full,omitempty"`
+}
+
+//InlayHintClientCapabilities describes client capabilities specific to the `textDocument/inlayHint` request.
+type InlayHintClientCapabilities struct {
+	DynamicRegistration *bool                    `json:"dynamicRegistration,omitempty"`
+	ResolveSupport      *inlayHintResolveSupport `json:"resolveSupport,omitempty"`
+}
+
+type inlayHintResolveSupport struct {
+	//Properties that a client can resolve lazily
+	Properties []string `json:"properties"`
+}
+
+//InlineValueClientCapabilities describes client capabilities specific to the `textDocument/inlineValue` request.
+type InlineValueClientCapabilities struct {
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+}
+
+//LinkedEditingRangeClientCapabilities describes client capabilities specific to the `textDocument/linkedEditingRange` request.
+type LinkedEditingRangeClientCapabilities struct {
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+}
+
+//MonikerClientCapabilities describes client capabilities specific to the `textDocument/moniker` request.
+type MonikerClientCapabilities struct {
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+}
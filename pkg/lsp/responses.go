@@ -21,28 +21,36 @@ type serverInfo struct {
 
 //ServerCapabilities represent the capabilities the language server provides.
 type ServerCapabilities struct {
-	TextDocumentSync          *textDocSyncOptionsOrLegacy  `json:"textDocumentSync,omitempty"`
-	CompletionProvider        *CompletionOptions           `json:"completionProvider,omitempty"`
-	HoverProvider             *hoverUnion                  `json:"hoverProvider,omitempty"`
-	SignatureHelpProvider     *SignatureHelpOptions        `json:"signatureHelpProvider,omitempty"`
-	DeclarationProvider       *declarationUnion            `json:"declarationProvider,omitempty"`
-	DefinitionProvider        *definitionUnion             `json:"definitionProvider,omitempty"`
-	TypeDefinitionProvider    *typeDefinitionUnion         `json:"typeDefinitionProvider,omitempty"`
-	ImplementationProvider    *implementationProviderUnion `json:"implementationProvider,omitempty"`
-	ReferencesProvider        *referencesUnion             `json:"referencesProvider,omitempty"`
-	DocumentHighlightProvider *documentHighlightUnion      `json:"documentHighlightProvider,omitempty"`
+	TextDocumentSync                 *textDocSyncOptionsOrLegacy      `json:"textDocumentSync,omitempty"`
+	CompletionProvider               *CompletionOptions               `json:"completionProvider,omitempty"`
+	HoverProvider                    *hoverUnion                      `json:"hoverProvider,omitempty"`
+	SignatureHelpProvider            *SignatureHelpOptions            `json:"signatureHelpProvider,omitempty"`
+	DeclarationProvider              *declarationUnion                `json:"declarationProvider,omitempty"`
+	DefinitionProvider               *definitionUnion                 `json:"definitionProvider,omitempty"`
+	TypeDefinitionProvider           *typeDefinitionUnion             `json:"typeDefinitionProvider,omitempty"`
+	ImplementationProvider           *implementationProviderUnion     `json:"implementationProvider,omitempty"`
+	ReferencesProvider               *referencesUnion                 `json:"referencesProvider,omitempty"`
+	DocumentHighlightProvider        *documentHighlightUnion          `json:"documentHighlightProvider,omitempty"`
+	CodeActionProvider               *codeActionUnion                 `json:"codeActionProvider,omitempty"`
+	CodeLensProvider                 *CodeLensOptions                 `json:"codeLensProvider,omitempty"`
+	DocumentLinkProvider             *DocumentLinkOptions             `json:"documentLinkProvider,omitempty"`
+	ColorProvider                    *colorUnion                      `json:"colorProvider,omitempty"`
+	DocumentFormattingProvider       *documentFormattingUnion         `json:"documentFormattingProvider,omitempty"`
+	DocumentRangeFormattingProvider  *documentRangeFormattingUnion    `json:"documentRangeFormattingProvider,omitempty"`
+	DocumentOnTypeFormattingProvider *DocumentOnTypeFormattingOptions `json:"documentOnTypeFormattingProvider,omitempty"`
+	RenameProvider                   *renameUnion                     `json:"renameProvider,omitempty"`
+	FoldingRangeProvider             *foldingRangeUnion               `json:"foldingRangeProvider,omitempty"`
+	SelectionRangeProvider           *selectionRangeUnion             `json:"selectionRangeProvider,omitempty"`
+	CallHierarchyProvider            *callHierarchyUnion              `json:"callHierarchyProvider,omitempty"`
+	TypeHierarchyProvider            *typeHierarchyUnion              `json:"typeHierarchyProvider,omitempty"`
+	SemanticTokensProvider           *SemanticTokensOptions           `json:"semanticTokensProvider,omitempty"`
+	InlayHintProvider                *inlayHintUnion                  `json:"inlayHintProvider,omitempty"`
+	InlineValueProvider              *inlineValueUnion                `json:"inlineValueProvider,omitempty"`
+	LinkedEditingRangeProvider       *linkedEditingRangeUnion         `json:"linkedEditingRangeProvider,omitempty"`
+	MonikerProvider                  *monikerUnion                    `json:"monikerProvider,omitempty"`
+	ExecuteCommandProvider           *ExecuteCommandOptions           `json:"executeCommandProvider,omitempty"`
 	//TODO: Complete the rest
 	// DocumentSymbolProvider           *documentSymbolUnion           `json:"documentSymbolProvider,omitempty"`
-	// CodeActionProvider               *codeActionUnion               `json:"codeActionProvider,omitempty"`
-	// CodeLensProvider                 *codeLensUnion                 `json:"codeLensProvider,omitempty"`
-	// DocumentLinkProvider             *DocumentLinkOptions           `json:"documentLinkProvider,omitempty"`
-	// ColorProvider                    *colorUnion                    `json:"colorProvider,omitempty"`
-	// DocumentFormattingProvider       *documentFormattingUnion       `json:"documentFormattingProvider,omitempty"`
-	// DocumentRangeFormattingProvider  *documentRangeFormattingUnion  `json:"documentRangeFormattingProvider,omitempty"`
-	// DocumentOnTypeFormattingProvider *documentOnTypeFormattingUnion `json:"documentOnTypeFormattingProvider,omitempty"`
-	// RenameProvider                   *renameUnion                   `json:"renameProvider,omitempty"`
-	// FoldingRangeProvider             *foldingRangeUnion             `json:"foldingRangeProvider,omitempty"`
-	// ExecuteCommandProvider           *ExecuteCommandOptions         `json:"executeCommandProvider,omitempty"`
 	WorkspaceSymbolProvider *bool                        `json:"workspaceSymbolProvider,omitempty"`
 	Workspace               *workspaceServerCapabilities `json:"workspace,omitempty"`
 	Experimental            *json.RawMessage             `json:"experimental,omitempty"`
@@ -331,7 +339,8 @@ func (ru *documentHighlightUnion) UnmarshalJSON(js []byte) error {
 }
 
 type workspaceServerCapabilities struct {
-	WorkspaceFolders WorkspaceFolderServerCapabilities
+	WorkspaceFolders WorkspaceFolderServerCapabilities `json:"workspaceFolders"`
+	FileOperations   *FileOperationOptions             `json:"fileOperations,omitempty"`
 }
 
 //WorkspaceFolderServerCapabilities indicates whether the server supports workspace folder
@@ -354,10 +363,13 @@ func (cn *changeNotifications) MarshalJSON() ([]byte, error) {
 
 func (cn *changeNotifications) UnmarshalJSON(js []byte) error {
 	*cn = changeNotifications{}
-	if err := json.Unmarshal(js, cn.Boolean); err != nil {
-		return json.Unmarshal(js, cn.ID)
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		cn.Boolean = &b
+		return nil
 	}
-	return nil
+	cn.ID = new(string)
+	return json.Unmarshal(js, cn.ID)
 }
 
 //PublishDiagnosticsParams are Diagnostics notification parameters  sent from the server to the client to signal results of validation runs
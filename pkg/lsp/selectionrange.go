@@ -0,0 +1,101 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//SelectionRangeParams are the parameters of a `textDocument/selectionRange` request
+type SelectionRangeParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//Positions inside the text document
+	Positions []code.Position `json:"positions"`
+}
+
+//SelectionRange is a range to select when expanding the selection, linked to the parent range that contains it
+type SelectionRange struct {
+	//Range of this selection range
+	Range code.Range `json:"range"`
+	//Parent selection range containing this range. Therefore `parent.range` must contain `this.range`
+	Parent *SelectionRange `json:"parent,omitempty"`
+}
+
+//SelectionRangeOptions are the server capabilities for selection ranges
+type SelectionRangeOptions struct {
+	*WorkDoneProgressOptions
+}
+
+//SelectionRangeRegistrationOptions are the registration options for selection ranges
+type SelectionRangeRegistrationOptions struct {
+	*SelectionRangeOptions
+	*TextDocumentRegistrationOptions
+	*StaticRegistrationOptions
+}
+
+type selectionRangeUnion struct {
+	Boolean             *bool
+	Options             *SelectionRangeOptions
+	RegistrationOptions *SelectionRangeRegistrationOptions
+}
+
+func (su *selectionRangeUnion) MarshalJSON() ([]byte, error) {
+	if su.Boolean != nil {
+		return json.Marshal(*su.Boolean)
+	}
+	if su.Options != nil {
+		return json.Marshal(*su.Options)
+	}
+	return json.Marshal(su.RegistrationOptions)
+}
+
+func (su *selectionRangeUnion) UnmarshalJSON(js []byte) error {
+	*su = selectionRangeUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		su.Boolean = &b
+		return nil
+	}
+	su.RegistrationOptions = &SelectionRangeRegistrationOptions{}
+	return json.Unmarshal(js, su.RegistrationOptions)
+}
+
+//SelectionRangeProvider is implemented by embedding servers that compute selection ranges for `textDocument/selectionRange`.
+//It returns one selection range per requested position, in the same order
+type SelectionRangeProvider interface {
+	SelectionRanges(params *SelectionRangeParams) ([]SelectionRange, error)
+}
+
+func (s *DefaultServer) selectionRange(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(SelectionRangeProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := SelectionRangeParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	ranges, err := provider.SelectionRanges(&params)
+	s.reply(req, ranges, err)
+}
+
+//NewSelectionRange links `ranges`, ordered from the innermost to the outermost, into a chain of selection ranges and returns the innermost one.
+//It is an error for a range not to strictly contain the range before it
+func NewSelectionRange(ranges ...code.Range) (*SelectionRange, error) {
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no ranges to build a selection range from")
+	}
+	var parent *SelectionRange
+	for i := len(ranges) - 1; i >= 0; i-- {
+		if parent != nil && (parent.Range == ranges[i] || !parent.Range.ContainsRange(ranges[i])) {
+			return nil, fmt.Errorf("selection range %d does not strictly contain selection range %d", i+1, i)
+		}
+		parent = &SelectionRange{Range: ranges[i], Parent: parent}
+	}
+	return parent, nil
+}
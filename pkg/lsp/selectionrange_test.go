@@ -0,0 +1,50 @@
+package lsp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+func TestNewSelectionRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []code.Range
+		err    string
+	}{
+		{name: "single", ranges: []code.Range{rng(1, 2, 1, 5)}},
+		{name: "nested", ranges: []code.Range{rng(1, 2, 1, 5), rng(1, 0, 1, 9), rng(0, 0, 3, 0)}},
+		{name: "shared start", ranges: []code.Range{rng(1, 2, 1, 5), rng(1, 2, 1, 9)}},
+		{name: "shared end", ranges: []code.Range{rng(1, 4, 1, 5), rng(0, 0, 1, 5)}},
+		{name: "empty innermost", ranges: []code.Range{rng(1, 3, 1, 3), rng(1, 2, 1, 5)}},
+		{name: "none", err: "no ranges"},
+		{name: "equal ranges", ranges: []code.Range{rng(1, 2, 1, 5), rng(1, 2, 1, 5)}, err: "range 1 does not strictly contain selection range 0"},
+		{name: "outermost first", ranges: []code.Range{rng(0, 0, 3, 0), rng(1, 2, 1, 5)}, err: "range 1 does not strictly contain selection range 0"},
+		{name: "parent ends early", ranges: []code.Range{rng(1, 2, 1, 5), rng(1, 0, 1, 4)}, err: "range 1 does not strictly contain selection range 0"},
+		{name: "disjoint", ranges: []code.Range{rng(1, 2, 1, 5), rng(2, 0, 3, 0)}, err: "range 1 does not strictly contain selection range 0"},
+		{name: "outer break", ranges: []code.Range{rng(1, 2, 1, 5), rng(1, 0, 1, 9), rng(1, 1, 2, 0)}, err: "range 2 does not strictly contain selection range 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSelectionRange(tt.ranges...)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("got %+v, %v, want an error containing %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			chain := []code.Range{}
+			for sr := got; sr != nil; sr = sr.Parent {
+				chain = append(chain, sr.Range)
+			}
+			if !reflect.DeepEqual(chain, tt.ranges) {
+				t.Errorf("got chain %v, want %v", chain, tt.ranges)
+			}
+		})
+	}
+}
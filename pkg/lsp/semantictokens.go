@@ -0,0 +1,452 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//The semantic token types predefined by the LSP. Servers may use other types as long as they are part of their legend
+const (
+	SemanticTokenNamespace     = "namespace"
+	SemanticTokenType          = "type"
+	SemanticTokenClass         = "class"
+	SemanticTokenEnum          = "enum"
+	SemanticTokenInterface     = "interface"
+	SemanticTokenStruct        = "struct"
+	SemanticTokenTypeParameter = "typeParameter"
+	SemanticTokenParameter     = "parameter"
+	SemanticTokenVariable      = "variable"
+	SemanticTokenProperty      = "property"
+	SemanticTokenEnumMember    = "enumMember"
+	SemanticTokenEvent         = "event"
+	SemanticTokenFunction      = "function"
+	SemanticTokenMethod        = "method"
+	SemanticTokenMacro         = "macro"
+	SemanticTokenKeyword       = "keyword"
+	SemanticTokenModifier      = "modifier"
+	SemanticTokenComment       = "comment"
+	SemanticTokenString        = "string"
+	SemanticTokenNumber        = "number"
+	SemanticTokenRegexp        = "regexp"
+	SemanticTokenOperator      = "operator"
+	SemanticTokenDecorator     = "decorator"
+)
+
+//The semantic token modifiers predefined by the LSP
+const (
+	SemanticTokenModifierDeclaration    = "declaration"
+	SemanticTokenModifierDefinition     = "definition"
+	SemanticTokenModifierReadonly       = "readonly"
+	SemanticTokenModifierStatic         = "static"
+	SemanticTokenModifierDeprecated     = "deprecated"
+	SemanticTokenModifierAbstract       = "abstract"
+	SemanticTokenModifierAsync          = "async"
+	SemanticTokenModifierModification   = "modification"
+	SemanticTokenModifierDocumentation  = "documentation"
+	SemanticTokenModifierDefaultLibrary = "defaultLibrary"
+)
+
+//SemanticTokensLegend lists the token types and modifiers a server uses. Tokens refer to types by their index in the legend,
+//and to modifiers by a bit set of their indices
+type SemanticTokensLegend struct {
+	//TokenTypes is the token types a server uses
+	TokenTypes []string `json:"tokenTypes"`
+	//TokenModifiers is the token modifiers a server uses
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+//NewSemanticTokensLegend creates an empty legend
+func NewSemanticTokensLegend() *SemanticTokensLegend {
+	return &SemanticTokensLegend{
+		TokenTypes:     []string{},
+		TokenModifiers: []string{},
+	}
+}
+
+//AddTokenTypes registers token types with the legend, ignoring those already registered
+func (l *SemanticTokensLegend) AddTokenTypes(types ...string) *SemanticTokensLegend {
+	l.TokenTypes = appendMissing(l.TokenTypes, types)
+	return l
+}
+
+//AddTokenModifiers registers token modifiers with the legend, ignoring those already registered.
+//At most 32 modifiers can be encoded
+func (l *SemanticTokensLegend) AddTokenModifiers(modifiers ...string) *SemanticTokensLegend {
+	l.TokenModifiers = appendMissing(l.TokenModifiers, modifiers)
+	return l
+}
+
+func appendMissing(list, items []string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+//SemanticToken is a token in absolute terms, before encoding
+type SemanticToken struct {
+	//Line of the token (zero-based)
+	Line int64
+	//Character offset of the start of the token on its line, in UTF-16 code units
+	Character int64
+	//Length of the token in UTF-16 code units
+	Length int64
+	//Type of the token, which must be part of the legend
+	Type string
+	//Modifiers of the token, which must be part of the legend
+	Modifiers []string
+}
+
+//Encode turns tokens into the relative integer encoding of the LSP: for each token, in document order, its line relative to the previous token,
+//its start character (relative to the previous token if on the same line), its length, the index of its type and the bit set of its modifiers.
+//It is an error for tokens to overlap, or to use types or modifiers that are not part of the legend
+func (l *SemanticTokensLegend) Encode(tokens []SemanticToken) ([]uint32, error) {
+	types := make(map[string]uint32, len(l.TokenTypes))
+	for i, t := range l.TokenTypes {
+		types[t] = uint32(i)
+	}
+	modifiers := make(map[string]int, len(l.TokenModifiers))
+	for i, m := range l.TokenModifiers {
+		modifiers[m] = i
+	}
+	sorted := make([]SemanticToken, len(tokens))
+	copy(sorted, tokens)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Line == sorted[j].Line {
+			return sorted[i].Character < sorted[j].Character
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+	data := make([]uint32, 0, 5*len(sorted))
+	var previous SemanticToken
+	for i, t := range sorted {
+		if t.Line < 0 || t.Character < 0 || t.Length < 0 {
+			return nil, fmt.Errorf("semantic token at %d:%d has a negative position or length", t.Line, t.Character)
+		}
+		typeIndex, ok := types[t.Type]
+		if !ok {
+			return nil, fmt.Errorf("semantic token type %q at %d:%d is not part of the legend", t.Type, t.Line, t.Character)
+		}
+		var modifierSet uint32
+		for _, m := range t.Modifiers {
+			index, ok := modifiers[m]
+			if !ok {
+				return nil, fmt.Errorf("semantic token modifier %q at %d:%d is not part of the legend", m, t.Line, t.Character)
+			}
+			if index >= 32 {
+				return nil, fmt.Errorf("semantic token modifier %q at %d:%d is modifier %d of the legend, but only the first 32 can be encoded", m, t.Line, t.Character, index+1)
+			}
+			modifierSet |= 1 << uint(index)
+		}
+		deltaLine, deltaStart := t.Line, t.Character
+		if i > 0 {
+			deltaLine = t.Line - previous.Line
+			if deltaLine == 0 {
+				if t.Character < previous.Character+previous.Length {
+					return nil, fmt.Errorf("semantic token at %d:%d overlaps the token at %d:%d", t.Line, t.Character, previous.Line, previous.Character)
+				}
+				deltaStart = t.Character - previous.Character
+			}
+		}
+		data = append(data, uint32(deltaLine), uint32(deltaStart), uint32(t.Length), typeIndex, modifierSet)
+		previous = t
+	}
+	return data, nil
+}
+
+//SemanticTokensParams are the parameters of a `textDocument/semanticTokens/full` request
+type SemanticTokensParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+//SemanticTokensDeltaParams are the parameters of a `textDocument/semanticTokens/full/delta` request
+type SemanticTokensDeltaParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//PreviousResultID is the result id of a previous response, which may either point to a full response or a delta response
+	PreviousResultID string `json:"previousResultId"`
+}
+
+//SemanticTokensRangeParams are the parameters of a `textDocument/semanticTokens/range` request
+type SemanticTokensRangeParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//Range the semantic tokens are requested for
+	Range code.Range `json:"range"`
+}
+
+//SemanticTokens are the encoded semantic tokens of a document
+type SemanticTokens struct {
+	//ResultID identifies this result, so that the next request can ask for a delta against it
+	ResultID *string `json:"resultId,omitempty"`
+	//Data are the encoded tokens
+	Data []uint32 `json:"data"`
+}
+
+//SemanticTokensDelta holds the edits turning the data of a previous result into the current one
+type SemanticTokensDelta struct {
+	ResultID *string `json:"resultId,omitempty"`
+	//Edits to the previous data, which must be applied in order
+	Edits []SemanticTokensEdit `json:"edits"`
+}
+
+//SemanticTokensEdit replaces `deleteCount` integers starting at index `start` of the previous data with `data`
+type SemanticTokensEdit struct {
+	Start       uint32   `json:"start"`
+	DeleteCount uint32   `json:"deleteCount"`
+	Data        []uint32 `json:"data,omitempty"`
+}
+
+//SemanticTokensOptions are the server capabilities for semantic tokens
+type SemanticTokensOptions struct {
+	*WorkDoneProgressOptions
+	//Legend used by the server
+	Legend SemanticTokensLegend `json:"legend"`
+	//Range indicates that the server supports providing semantic tokens for a specific range of a document
+	Range *semanticTokensRequestUnion `json:"range,omitempty"`
+	//Full indicates that the server supports providing semantic tokens for a full document, and possibly deltas
+	Full *semanticTokensRequestUnion `json:"full,omitempty"`
+}
+
+//semanticTokensRequestUnion declares support for a semantic tokens request, either as a boolean or as an object that may support deltas
+type semanticTokensRequestUnion struct {
+	Boolean *bool
+	Options *semanticTokensRequestOptions
+}
+
+type semanticTokensRequestOptions struct {
+	Delta *bool `json:"delta,omitempty"`
+}
+
+func (su *semanticTokensRequestUnion) MarshalJSON() ([]byte, error) {
+	if su.Boolean != nil {
+		return json.Marshal(*su.Boolean)
+	}
+	return json.Marshal(su.Options)
+}
+
+func (su *semanticTokensRequestUnion) UnmarshalJSON(js []byte) error {
+	*su = semanticTokensRequestUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		su.Boolean = &b
+		return nil
+	}
+	su.Options = &semanticTokensRequestOptions{}
+	return json.Unmarshal(js, su.Options)
+}
+
+func (su *semanticTokensRequestUnion) supportsDelta() bool {
+	return su != nil && su.Options != nil && su.Options.Delta != nil && *su.Options.Delta
+}
+
+//SemanticTokensProvider is implemented by embedding servers that compute semantic tokens. The tokens are encoded with the legend and,
+//for `textDocument/semanticTokens/full/delta`, sent as a delta against the previous result for the document
+type SemanticTokensProvider interface {
+	SemanticTokensLegend() *SemanticTokensLegend
+	SemanticTokens(params *SemanticTokensParams) ([]SemanticToken, error)
+}
+
+//SemanticTokensRangeProvider is optionally implemented by a `SemanticTokensProvider` to compute the tokens of a range of a document
+type SemanticTokensRangeProvider interface {
+	SemanticTokensRange(params *SemanticTokensRangeParams) ([]SemanticToken, error)
+}
+
+func (s *DefaultServer) semanticTokensCapability(p SemanticTokensProvider) *SemanticTokensOptions {
+	cache := s.semanticTokensCache(p)
+	delta := true
+	options := SemanticTokensOptions{
+		Legend: *cache.legend,
+		Full:   &semanticTokensRequestUnion{Options: &semanticTokensRequestOptions{Delta: &delta}},
+	}
+	if _, ok := p.(SemanticTokensRangeProvider); ok {
+		supported := true
+		options.Range = &semanticTokensRequestUnion{Boolean: &supported}
+	}
+	return &options
+}
+
+func (s *DefaultServer) semanticTokensCache(p SemanticTokensProvider) *semanticTokensCache {
+	if s.semanticTokens == nil {
+		s.semanticTokens = &semanticTokensCache{
+			legend:  p.SemanticTokensLegend(),
+			results: make(map[code.DocumentURI]semanticTokensResult),
+		}
+		s.addDocumentListener(s.semanticTokens)
+	}
+	return s.semanticTokens
+}
+
+func (s *DefaultServer) semanticTokensFull(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(SemanticTokensProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := SemanticTokensParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	cache := s.semanticTokensCache(provider)
+	tokens, err := provider.SemanticTokens(&params)
+	if err != nil {
+		s.reply(req, nil, err)
+		return
+	}
+	data, err := cache.legend.Encode(tokens)
+	if err != nil {
+		s.reply(req, nil, err)
+		return
+	}
+	s.reply(req, cache.full(params.TextDocument.URI, data), nil)
+}
+
+func (s *DefaultServer) semanticTokensFullDelta(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(SemanticTokensProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := SemanticTokensDeltaParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	cache := s.semanticTokensCache(provider)
+	tokens, err := provider.SemanticTokens(&SemanticTokensParams{TextDocument: params.TextDocument})
+	if err != nil {
+		s.reply(req, nil, err)
+		return
+	}
+	data, err := cache.legend.Encode(tokens)
+	if err != nil {
+		s.reply(req, nil, err)
+		return
+	}
+	s.reply(req, cache.delta(params.TextDocument.URI, params.PreviousResultID, data), nil)
+}
+
+func (s *DefaultServer) semanticTokensRange(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(SemanticTokensRangeProvider)
+	legendProvider, hasLegend := s.provider().(SemanticTokensProvider)
+	if !ok || !hasLegend {
+		s.forward(req)
+		return
+	}
+	params := SemanticTokensRangeParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	tokens, err := provider.SemanticTokensRange(&params)
+	if err != nil {
+		s.reply(req, nil, err)
+		return
+	}
+	data, err := s.semanticTokensCache(legendProvider).legend.Encode(tokens)
+	if err != nil {
+		s.reply(req, nil, err)
+		return
+	}
+	s.reply(req, SemanticTokens{Data: data}, nil)
+}
+
+//RefreshSemanticTokens sends `workspace/semanticTokens/refresh` to ask the client to re-request the semantic tokens of all documents,
+//if the client supports it. Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) RefreshSemanticTokens(ctx context.Context) error {
+	var supported *bool
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc != nil && wc.SemanticTokens != nil {
+		supported = wc.SemanticTokens.RefreshSupport
+	}
+	return s.refresh(ctx, "workspace/semanticTokens/refresh", supported)
+}
+
+//semanticTokensCache remembers the last semantic tokens sent for each document, to compute deltas against them
+type semanticTokensCache struct {
+	legend  *SemanticTokensLegend
+	mutex   sync.Mutex
+	lastID  int64
+	results map[code.DocumentURI]semanticTokensResult
+}
+
+type semanticTokensResult struct {
+	id   string
+	data []uint32
+}
+
+func (c *semanticTokensCache) full(uri code.DocumentURI, data []uint32) SemanticTokens {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	id := c.store(uri, data)
+	return SemanticTokens{ResultID: &id, Data: data}
+}
+
+//delta returns the edits from the previous result to `data`, or the full tokens if the previous result is not known
+func (c *semanticTokensCache) delta(uri code.DocumentURI, previousID string, data []uint32) interface{} {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	previous, ok := c.results[uri]
+	id := c.store(uri, data)
+	if !ok || previous.id != previousID {
+		return SemanticTokens{ResultID: &id, Data: data}
+	}
+	return SemanticTokensDelta{ResultID: &id, Edits: semanticTokensEdits(previous.data, data)}
+}
+
+//DocumentChanged does nothing: a delta is computed against the result whose ID the client sends, which stays valid
+//after the document changes, so the last result of the document must be kept until it is closed
+func (c *semanticTokensCache) DocumentChanged(uri code.DocumentURI, version int64) {
+}
+
+//DocumentClosed drops the last result of the document, since the client will not ask for a delta of it again
+func (c *semanticTokensCache) DocumentClosed(uri code.DocumentURI) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.results, uri)
+}
+
+func (c *semanticTokensCache) store(uri code.DocumentURI, data []uint32) string {
+	c.lastID++
+	id := strconv.FormatInt(c.lastID, 10)
+	c.results[uri] = semanticTokensResult{id: id, data: data}
+	return id
+}
+
+//semanticTokensEdits computes a single edit replacing the part of `previous` that differs from `current`, or no edit if they are the same
+func semanticTokensEdits(previous, current []uint32) []SemanticTokensEdit {
+	prefix := 0
+	for prefix < len(previous) && prefix < len(current) && previous[prefix] == current[prefix] {
+		prefix++
+	}
+	if prefix == len(previous) && prefix == len(current) {
+		return []SemanticTokensEdit{}
+	}
+	suffix := 0
+	for suffix < len(previous)-prefix && suffix < len(current)-prefix &&
+		previous[len(previous)-1-suffix] == current[len(current)-1-suffix] {
+		suffix++
+	}
+	return []SemanticTokensEdit{{
+		Start:       uint32(prefix),
+		DeleteCount: uint32(len(previous) - prefix - suffix),
+		Data:        current[prefix : len(current)-suffix],
+	}}
+}
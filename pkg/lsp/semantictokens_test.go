@@ -0,0 +1,181 @@
+package lsp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSemanticTokensEncode(t *testing.T) {
+	legend := NewSemanticTokensLegend().
+		AddTokenTypes(SemanticTokenKeyword, SemanticTokenFunction, SemanticTokenKeyword).
+		AddTokenModifiers(SemanticTokenModifierDeclaration, SemanticTokenModifierReadonly)
+	for i := 0; i < 31; i++ {
+		legend.AddTokenModifiers(fmt.Sprintf("modifier%d", i))
+	}
+	tests := []struct {
+		name   string
+		tokens []SemanticToken
+		want   []uint32
+		err    string
+	}{
+		{name: "none", want: []uint32{}},
+		{
+			name: "relative positions",
+			tokens: []SemanticToken{
+				{Line: 2, Character: 5, Length: 3, Type: SemanticTokenKeyword},
+				{Line: 2, Character: 10, Length: 4, Type: SemanticTokenFunction, Modifiers: []string{SemanticTokenModifierReadonly, SemanticTokenModifierDeclaration}},
+				{Line: 5, Character: 1, Length: 2, Type: SemanticTokenFunction},
+			},
+			want: []uint32{2, 5, 3, 0, 0, 0, 5, 4, 1, 3, 3, 1, 2, 1, 0},
+		},
+		{
+			name: "sorted",
+			tokens: []SemanticToken{
+				{Line: 1, Character: 0, Length: 1, Type: SemanticTokenKeyword},
+				{Line: 0, Character: 4, Length: 1, Type: SemanticTokenKeyword},
+				{Line: 0, Character: 0, Length: 1, Type: SemanticTokenKeyword},
+			},
+			want: []uint32{0, 0, 1, 0, 0, 0, 4, 1, 0, 0, 1, 0, 1, 0, 0},
+		},
+		{
+			name:   "last encodable modifier",
+			tokens: []SemanticToken{{Length: 1, Type: SemanticTokenKeyword, Modifiers: []string{"modifier29"}}},
+			want:   []uint32{0, 0, 1, 0, 1 << 31},
+		},
+		{
+			name:   "modifier past bit 31",
+			tokens: []SemanticToken{{Length: 1, Type: SemanticTokenKeyword, Modifiers: []string{"modifier30"}}},
+			err:    "only the first 32 can be encoded",
+		},
+		{
+			name:   "unknown type",
+			tokens: []SemanticToken{{Length: 1, Type: SemanticTokenMacro}},
+			err:    "not part of the legend",
+		},
+		{
+			name:   "unknown modifier",
+			tokens: []SemanticToken{{Length: 1, Type: SemanticTokenKeyword, Modifiers: []string{SemanticTokenModifierAsync}}},
+			err:    "not part of the legend",
+		},
+		{
+			name: "overlap",
+			tokens: []SemanticToken{
+				{Line: 0, Character: 0, Length: 5, Type: SemanticTokenKeyword},
+				{Line: 0, Character: 3, Length: 1, Type: SemanticTokenKeyword},
+			},
+			err: "overlaps",
+		},
+		{
+			name:   "negative",
+			tokens: []SemanticToken{{Line: -1, Length: 1, Type: SemanticTokenKeyword}},
+			err:    "negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := legend.Encode(tt.tokens)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("got error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+//applySemanticTokensEdits applies edits to semantic token data the way a client does
+func applySemanticTokensEdits(data []uint32, edits []SemanticTokensEdit) []uint32 {
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		result := append([]uint32{}, data[:e.Start]...)
+		result = append(result, e.Data...)
+		data = append(result, data[e.Start+e.DeleteCount:]...)
+	}
+	return data
+}
+
+func TestSemanticTokensEdits(t *testing.T) {
+	tests := []struct {
+		name              string
+		previous, current []uint32
+		want              []SemanticTokensEdit
+	}{
+		{name: "same", previous: []uint32{1, 2, 3}, current: []uint32{1, 2, 3}, want: []SemanticTokensEdit{}},
+		{name: "both empty", previous: []uint32{}, current: []uint32{}, want: []SemanticTokensEdit{}},
+		{name: "from empty", previous: []uint32{}, current: []uint32{1, 2}, want: []SemanticTokensEdit{{Start: 0, DeleteCount: 0, Data: []uint32{1, 2}}}},
+		{name: "to empty", previous: []uint32{1, 2}, current: []uint32{}, want: []SemanticTokensEdit{{Start: 0, DeleteCount: 2, Data: []uint32{}}}},
+		{name: "middle", previous: []uint32{1, 2, 3, 4}, current: []uint32{1, 9, 9, 4}, want: []SemanticTokensEdit{{Start: 1, DeleteCount: 2, Data: []uint32{9, 9}}}},
+		{name: "insert", previous: []uint32{1, 4}, current: []uint32{1, 2, 3, 4}, want: []SemanticTokensEdit{{Start: 1, DeleteCount: 0, Data: []uint32{2, 3}}}},
+		{name: "delete", previous: []uint32{1, 2, 3, 4}, current: []uint32{1, 4}, want: []SemanticTokensEdit{{Start: 1, DeleteCount: 2, Data: []uint32{}}}},
+		{name: "repeated values", previous: []uint32{0, 0, 0}, current: []uint32{0, 0, 0, 0}, want: []SemanticTokensEdit{{Start: 3, DeleteCount: 0, Data: []uint32{0}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := semanticTokensEdits(tt.previous, tt.current)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if applied := applySemanticTokensEdits(tt.previous, got); !reflect.DeepEqual(applied, tt.current) {
+				t.Errorf("applying the edits gave %v, want %v", applied, tt.current)
+			}
+		})
+	}
+}
+
+//semanticTokensServer reports the tokens it is set to
+type semanticTokensServer struct {
+	*testServer
+	tokens []SemanticToken
+}
+
+func (s *semanticTokensServer) SemanticTokensLegend() *SemanticTokensLegend {
+	return NewSemanticTokensLegend().AddTokenTypes(SemanticTokenKeyword)
+}
+
+func (s *semanticTokensServer) SemanticTokens(params *SemanticTokensParams) ([]SemanticToken, error) {
+	return s.tokens, nil
+}
+
+func TestSemanticTokensDeltas(t *testing.T) {
+	s := &semanticTokensServer{testServer: newTestServer()}
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{}}`)
+	document := map[string]string{"uri": "file:///a.dsl"}
+
+	s.tokens = []SemanticToken{{Line: 0, Character: 0, Length: 2, Type: SemanticTokenKeyword}}
+	c.request(1, "textDocument/semanticTokens/full", map[string]interface{}{"textDocument": document})
+	full := SemanticTokens{}
+	decode(t, c.next().Result, &full)
+	if full.ResultID == nil {
+		t.Fatal("full semantic tokens without a result id")
+	}
+
+	//the result stays available for deltas after the document changes
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": "file:///a.dsl", "version": 2},
+		"contentChanges": []map[string]string{{"text": "ab\nabc"}},
+	})
+	s.expectForwarded(t, "textDocument/didChange")
+	s.tokens = append(s.tokens, SemanticToken{Line: 1, Character: 0, Length: 3, Type: SemanticTokenKeyword})
+	c.request(2, "textDocument/semanticTokens/full/delta", map[string]interface{}{"textDocument": document, "previousResultId": *full.ResultID})
+	delta := SemanticTokensDelta{}
+	decode(t, c.next().Result, &delta)
+	if want := []SemanticTokensEdit{{Start: 5, Data: []uint32{1, 0, 3, 0, 0}}}; !reflect.DeepEqual(delta.Edits, want) {
+		t.Errorf("got delta %+v, want %+v", delta.Edits, want)
+	}
+
+	c.notify("textDocument/didClose", map[string]interface{}{"textDocument": document})
+	s.expectForwarded(t, "textDocument/didClose")
+	c.request(3, "textDocument/semanticTokens/full/delta", map[string]interface{}{"textDocument": document, "previousResultId": *delta.ResultID})
+	result := map[string]interface{}{}
+	decode(t, c.next().Result, &result)
+	if _, ok := result["data"]; !ok {
+		t.Errorf("got %v, want the full tokens of a document whose result was evicted on close", result)
+	}
+}
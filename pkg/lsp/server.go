@@ -1,9 +1,15 @@
 package lsp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
 )
@@ -37,11 +43,35 @@ func NewServer() Server {
 type DefaultServer struct {
 	// io jsonrpc2.Stream
 	*jsonrpc2.DefaultTransport
-	initialized             bool
-	receivedShutdownRequest bool
-	embeddingServer         *DefaultMethodProvider
+	initialized                  bool
+	receivedShutdownRequest      bool
+	embeddingServer              *DefaultMethodProvider
+	clientCapabilities           ClientCapabilities
+	stateMutex                   sync.RWMutex //guards the transport and client capabilities read by goroutines outside the `Start` loop
+	callMutex                    sync.Mutex
+	lastCallID                   int64
+	pendingCalls                 map[string]chan *jsonrpc2.Response
+	commands                     map[string]reflect.Value
+	commandMutex                 sync.Mutex
+	runningCommands              map[string]context.CancelFunc
+	semanticTokens               *semanticTokensCache
+	listenerMutex                sync.Mutex
+	documentListeners            []documentListener
+	lastRegistrationID           int64
+	fileWatchers                 []compiledFileSystemWatcher
+	compiledFileOperationFilters map[string][]compiledFileOperationFilter
+	pollInterval                 time.Duration
+	poller                       *pollingWatcher
+	workspace                    *Workspace
+	workspaceOnce                sync.Once
+	settingsMutex                sync.Mutex
+	settings                     []*Settings
+	settingsRegistered           bool //whether `workspace/didChangeConfiguration` is registered with the client, so that new settings must be too
 }
 
+//errConnectionClosed is returned by calls to the client that were pending when the input stream was closed
+var errConnectionClosed = errors.New("connection to the client closed")
+
 //Init passes in a reference to the embedding struct to allow calling its `Default` method
 func (s *DefaultServer) Init(composedServer DefaultMethodProvider) {
 	s.embeddingServer = &composedServer
@@ -84,12 +114,18 @@ func (s *DefaultServer) sendErrorResponse(id *jsonrpc2.ID, err *jsonrpc2.Error)
 //Start starts the LSP server protocol by reading the stream in a loop and dispatching the data to
 //well-defined methods, unknown RPC method are dispatched to the Default handler
 func (s *DefaultServer) Start(in io.Reader, out io.Writer) {
+	s.stateMutex.Lock()
 	s.DefaultTransport = jsonrpc2.MakeTransport(jsonrpc2.NewStream(in, out))
+	s.stateMutex.Unlock()
 	// s.io = jsonrpc2.NewStream(in, out)
 	for {
 		if buf, _, err := s.Read(); err == nil {
 			req := &jsonrpc2.Request{}
 			if err := json.Unmarshal(buf, req); err == nil {
+				if req.Method == "" && req.ID != nil {
+					s.deliverResponse(buf)
+					continue
+				}
 				switch req.Method {
 				case "initialize":
 					s.Initialize(req)
@@ -97,12 +133,94 @@ func (s *DefaultServer) Start(in io.Reader, out io.Writer) {
 					s.Initialized(req)
 				case "shutdown":
 					go s.Shutdown(req)
+				case "textDocument/didChange":
+					s.didChangeTextDocument(req)
+				case "textDocument/didClose":
+					s.didCloseTextDocument(req)
+				case "textDocument/codeAction":
+					s.codeAction(req)
+				case "codeAction/resolve":
+					s.resolveCodeAction(req)
+				case "textDocument/codeLens":
+					s.codeLens(req)
+				case "codeLens/resolve":
+					s.resolveCodeLens(req)
+				case "textDocument/documentLink":
+					s.documentLink(req)
+				case "documentLink/resolve":
+					s.resolveDocumentLink(req)
+				case "textDocument/documentColor":
+					s.documentColor(req)
+				case "textDocument/colorPresentation":
+					s.colorPresentation(req)
+				case "textDocument/formatting":
+					s.formatting(req)
+				case "textDocument/rangeFormatting":
+					s.rangeFormatting(req)
+				case "textDocument/onTypeFormatting":
+					s.onTypeFormatting(req)
+				case "textDocument/rename":
+					s.rename(req)
+				case "textDocument/prepareRename":
+					s.prepareRename(req)
+				case "textDocument/foldingRange":
+					s.foldingRange(req)
+				case "textDocument/selectionRange":
+					s.selectionRange(req)
+				case "textDocument/prepareCallHierarchy":
+					s.prepareCallHierarchy(req)
+				case "callHierarchy/incomingCalls":
+					s.incomingCalls(req)
+				case "callHierarchy/outgoingCalls":
+					s.outgoingCalls(req)
+				case "textDocument/prepareTypeHierarchy":
+					s.prepareTypeHierarchy(req)
+				case "typeHierarchy/supertypes":
+					s.supertypes(req)
+				case "typeHierarchy/subtypes":
+					s.subtypes(req)
+				case "textDocument/semanticTokens/full":
+					s.semanticTokensFull(req)
+				case "textDocument/semanticTokens/full/delta":
+					s.semanticTokensFullDelta(req)
+				case "textDocument/semanticTokens/range":
+					s.semanticTokensRange(req)
+				case "textDocument/inlayHint":
+					s.inlayHint(req)
+				case "inlayHint/resolve":
+					s.resolveInlayHint(req)
+				case "textDocument/inlineValue":
+					s.inlineValue(req)
+				case "textDocument/linkedEditingRange":
+					s.linkedEditingRange(req)
+				case "textDocument/moniker":
+					s.moniker(req)
+				case "workspace/willCreateFiles":
+					s.willCreateFiles(req)
+				case "workspace/didCreateFiles":
+					s.didCreateFiles(req)
+				case "workspace/willRenameFiles":
+					s.willRenameFiles(req)
+				case "workspace/didRenameFiles":
+					s.didRenameFiles(req)
+				case "workspace/willDeleteFiles":
+					s.willDeleteFiles(req)
+				case "workspace/didDeleteFiles":
+					s.didDeleteFiles(req)
+				case "workspace/didChangeWatchedFiles":
+					s.didChangeWatchedFiles(req)
+				case "workspace/didChangeWorkspaceFolders":
+					s.didChangeWorkspaceFolders(req)
+				case "workspace/didChangeConfiguration":
+					s.didChangeConfiguration(req)
+				case "workspace/executeCommand":
+					s.executeCommand(req)
+				case "$/cancelRequest":
+					s.cancelRequest(req)
 				default:
 					s.forward(req)
 				}
 			} else {
-				f.WriteString("Got Error " + err.Error() + "\nMessage: " + string(buf))
-
 				//TODO: determine what to do on receipt of a malformed request
 			}
 		} else {
@@ -111,6 +229,95 @@ func (s *DefaultServer) Start(in io.Reader, out io.Writer) {
 			}
 		}
 	}
+	s.abandonCalls()
+	s.cancelCommands()
+	if s.poller != nil {
+		s.poller.close()
+	}
+}
+
+//Call sends the request `method` with `params` from the server to the client and waits for the response, decoding its result into `result` unless it is nil.
+//The response is read by the `Start` loop, so Call must not be invoked from a handler running on that loop: use a separate goroutine instead
+func (s *DefaultServer) Call(ctx context.Context, method string, params, result interface{}) error {
+	transport := s.transport()
+	if transport == nil {
+		return errConnectionClosed
+	}
+	s.callMutex.Lock()
+	if s.pendingCalls == nil {
+		s.pendingCalls = make(map[string]chan *jsonrpc2.Response)
+	}
+	s.lastCallID++
+	id := jsonrpc2.ID{NumberID: s.lastCallID}
+	responses := make(chan *jsonrpc2.Response, 1)
+	s.pendingCalls[id.String()] = responses
+	s.callMutex.Unlock()
+
+	defer func() {
+		s.callMutex.Lock()
+		delete(s.pendingCalls, id.String())
+		s.callMutex.Unlock()
+	}()
+
+	if err := transport.SendRequest(&id, method, params); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case response := <-responses:
+		if response == nil {
+			return errConnectionClosed
+		}
+		if response.Error != nil {
+			return response.Error
+		}
+		if result == nil || response.Result == nil {
+			return nil
+		}
+		return json.Unmarshal(*response.Result, result)
+	}
+}
+
+//refresh asks the client to refresh the results of a feature, e.g. with `workspace/codeLens/refresh`.
+//Nothing is sent if the client does not support the refresh request
+func (s *DefaultServer) refresh(ctx context.Context, method string, supported *bool) error {
+	if supported == nil || !*supported {
+		return nil
+	}
+	return s.Call(ctx, method, nil, nil)
+}
+
+//deliverResponse hands a response received from the client to the pending call awaiting it.
+//Calls are sent with number IDs, but clients may echo them back as strings, so "7" is matched to the call with ID 7
+func (s *DefaultServer) deliverResponse(buf []byte) {
+	response := &jsonrpc2.Response{}
+	if err := json.Unmarshal(buf, response); err != nil || response.ID == nil {
+		return
+	}
+	id := *response.ID
+	if id.StringID != "" {
+		if n, err := strconv.ParseInt(id.StringID, 10, 64); err == nil {
+			id = jsonrpc2.ID{NumberID: n}
+		}
+	}
+	s.callMutex.Lock()
+	responses, ok := s.pendingCalls[id.String()]
+	delete(s.pendingCalls, id.String())
+	s.callMutex.Unlock()
+	if ok {
+		responses <- response
+	}
+}
+
+//abandonCalls releases calls still waiting for a response once the client can no longer send one
+func (s *DefaultServer) abandonCalls() {
+	s.callMutex.Lock()
+	defer s.callMutex.Unlock()
+	for id, responses := range s.pendingCalls {
+		responses <- nil
+		delete(s.pendingCalls, id)
+	}
 }
 
 //Stop gives the server an opportunity to do any clean up as may be required
@@ -119,17 +326,88 @@ func (s *DefaultServer) Stop() {
 
 //forward forwards the request to the embedding server's default handler
 func (s *DefaultServer) forward(req *jsonrpc2.Request) {
-	// f.WriteString(fmt.Sprintf("\n\n%s, %s\n\n", req.Method, req.Params))
 	if s.embeddingServer != nil {
 		(*s.embeddingServer).Default(req)
 	}
 }
 
+//provider returns the embedding server, which may implement optional feature provider interfaces
+//such as `CodeActionProvider`. It returns nil if `Init` has not been called
+func (s *DefaultServer) provider() DefaultMethodProvider {
+	if s.embeddingServer == nil {
+		return nil
+	}
+	return *s.embeddingServer
+}
+
+//ClientCapabilities returns the capabilities the client declared in its initialize request
+func (s *DefaultServer) ClientCapabilities() ClientCapabilities {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+	return s.clientCapabilities
+}
+
+//transport returns the transport to the client, or nil if the server has not been started
+func (s *DefaultServer) transport() *jsonrpc2.DefaultTransport {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+	return s.DefaultTransport
+}
+
+//decodeParams unmarshals the parameters of a request into `params`, returning an invalid params error on failure
+func decodeParams(req *jsonrpc2.Request, params interface{}) *jsonrpc2.Error {
+	if req.Params == nil {
+		return &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: "missing params for " + req.Method,
+		}
+	}
+	if err := json.Unmarshal(*req.Params, params); err != nil {
+		return &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+//reply sends `result` in response to `req`, or an error response if `err` is not nil.
+//Errors of type *jsonrpc2.Error are sent as is, any other error is reported as an internal error
+func (s *DefaultServer) reply(req *jsonrpc2.Request, result interface{}, err error) {
+	if err != nil {
+		rpcErr, ok := err.(*jsonrpc2.Error)
+		if !ok {
+			rpcErr = &jsonrpc2.Error{
+				Code:    jsonrpc2.CodeInternalError,
+				Message: err.Error(),
+			}
+		}
+		s.SendErrorResponse(req.ID, rpcErr)
+		return
+	}
+	if err := s.SendResponse(req.ID, result); err != nil {
+		s.SendErrorResponse(req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInternalError,
+			Message: err.Error(),
+		})
+	}
+}
+
 //Initialize the initialize request is sent as the first request from the client to the server. If the server receives a request or notification before the initialize request it should act as follows:
 // * For a request the response should be an error with code: -32002. The message can be picked by the server.
 // * Notifications should be dropped, except for the exit notification. This will allow the exit of a server without an initialize request.
 //see https://microsoft.github.io/language-server-protocol/specifications/specification-3-15/#initialize
 func (s *DefaultServer) Initialize(req *jsonrpc2.Request) {
+	params := InitializeParams{}
+	if req.Params != nil {
+		if err := json.Unmarshal(*req.Params, &params); err == nil {
+			s.stateMutex.Lock()
+			s.clientCapabilities = params.Capabilities
+			s.stateMutex.Unlock()
+			s.Workspace().SetFolders(initialWorkspaceFolders(params))
+		}
+	}
+
 	supported := true
 	syncKind := textDocumentSyncKind(1)
 	result := InitializeResult{
@@ -141,11 +419,19 @@ func (s *DefaultServer) Initialize(req *jsonrpc2.Request) {
 			},
 			Workspace: &workspaceServerCapabilities{
 				WorkspaceFolders: WorkspaceFolderServerCapabilities{
-					Supported: &supported,
+					Supported:           &supported,
+					ChangeNotifications: &changeNotifications{Boolean: &supported},
 				},
 			},
 		},
 	}
+	s.registerCodeActionEditCommand()
+	s.addProviderCapabilities(&result.Capabilities)
+	if len(s.commands) > 0 {
+		result.Capabilities.ExecuteCommandProvider = &ExecuteCommandOptions{
+			Commands: s.commandNames(),
+		}
+	}
 
 	if err := s.SendResponse(req.ID, result); err != nil {
 		e := jsonrpc2.Error{
@@ -158,12 +444,101 @@ func (s *DefaultServer) Initialize(req *jsonrpc2.Request) {
 	s.forward(req)
 }
 
+//addProviderCapabilities advertises the features whose provider interfaces are implemented by the embedding server
+func (s *DefaultServer) addProviderCapabilities(capabilities *ServerCapabilities) {
+	provider := s.provider()
+	if provider == nil {
+		return
+	}
+	if p, ok := provider.(CodeActionProvider); ok {
+		capabilities.CodeActionProvider = s.codeActionCapability(p)
+	}
+	if _, ok := provider.(CodeLensProvider); ok {
+		_, resolve := provider.(CodeLensResolver)
+		capabilities.CodeLensProvider = &CodeLensOptions{ResolveProvider: &resolve}
+	}
+	if _, ok := provider.(DocumentLinkProvider); ok {
+		_, resolve := provider.(DocumentLinkResolver)
+		capabilities.DocumentLinkProvider = &DocumentLinkOptions{ResolveProvider: &resolve}
+	}
+	if _, ok := provider.(DocumentColorProvider); ok {
+		supported := true
+		capabilities.ColorProvider = &colorUnion{Boolean: &supported}
+	}
+	if _, ok := provider.(DocumentFormattingProvider); ok {
+		supported := true
+		capabilities.DocumentFormattingProvider = &documentFormattingUnion{Boolean: &supported}
+	}
+	if _, ok := provider.(DocumentRangeFormattingProvider); ok {
+		supported := true
+		capabilities.DocumentRangeFormattingProvider = &documentRangeFormattingUnion{Boolean: &supported}
+	}
+	if p, ok := provider.(DocumentOnTypeFormattingProvider); ok {
+		first, more := p.OnTypeFormattingTriggerCharacters()
+		capabilities.DocumentOnTypeFormattingProvider = &DocumentOnTypeFormattingOptions{
+			FirstTriggerCharacter: first,
+			MoreTriggerCharacter:  more,
+		}
+	}
+	if _, ok := provider.(RenameProvider); ok {
+		capabilities.RenameProvider = s.renameCapability()
+	}
+	if _, ok := provider.(FoldingRangeProvider); ok {
+		supported := true
+		capabilities.FoldingRangeProvider = &foldingRangeUnion{Boolean: &supported}
+	}
+	if _, ok := provider.(SelectionRangeProvider); ok {
+		supported := true
+		capabilities.SelectionRangeProvider = &selectionRangeUnion{Boolean: &supported}
+	}
+	if _, ok := provider.(CallHierarchyProvider); ok {
+		supported := true
+		capabilities.CallHierarchyProvider = &callHierarchyUnion{Boolean: &supported}
+	}
+	if _, ok := provider.(TypeHierarchyProvider); ok {
+		supported := true
+		capabilities.TypeHierarchyProvider = &typeHierarchyUnion{Boolean: &supported}
+	}
+	if p, ok := provider.(SemanticTokensProvider); ok {
+		capabilities.SemanticTokensProvider = s.semanticTokensCapability(p)
+	}
+	if _, ok := provider.(InlayHintProvider); ok {
+		capabilities.InlayHintProvider = s.inlayHintCapability()
+	}
+	if _, ok := provider.(InlineValueProvider); ok {
+		supported := true
+		capabilities.InlineValueProvider = &inlineValueUnion{Boolean: &supported}
+	}
+	if _, ok := provider.(LinkedEditingRangeProvider); ok {
+		supported := true
+		capabilities.LinkedEditingRangeProvider = &linkedEditingRangeUnion{Boolean: &supported}
+	}
+	if _, ok := provider.(MonikerProvider); ok {
+		supported := true
+		capabilities.MonikerProvider = &monikerUnion{Boolean: &supported}
+	}
+	if capabilities.Workspace != nil {
+		capabilities.Workspace.FileOperations = s.fileOperationsCapability()
+	}
+}
+
+//NewWorkspaceEditBuilder creates a workspace edit builder for the workspace edit capabilities of the client
+func (s *DefaultServer) NewWorkspaceEditBuilder() *WorkspaceEditBuilder {
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc != nil {
+		return NewWorkspaceEditBuilder(wc.WorkspaceEdit)
+	}
+	return NewWorkspaceEditBuilder(nil)
+}
+
 //Initialized is called when the initialized notification is sent from the client to the server
 //after the client received the result of the initialize request but before the client is sending
 // any other request or notification to the server.
 // see https://microsoft.github.io/language-server-protocol/specifications/specification-3-15/#initialized
 func (s *DefaultServer) Initialized(req *jsonrpc2.Request) {
 	s.initialized = true
+	s.watchFiles()
+	s.pollFiles()
+	s.registerSettings()
 	s.forward(req)
 }
 
@@ -0,0 +1,220 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//testMessage is any message the server sends to the test client
+type testMessage struct {
+	ID     *jsonrpc2.ID    `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonrpc2.Error `json:"error"`
+}
+
+//testClient plays the client side of the protocol over pipes connected to a running server
+type testClient struct {
+	t        *testing.T
+	stream   jsonrpc2.Stream
+	messages chan testMessage
+}
+
+//startTestServer starts `s`, composed with `embedding`, and returns a client connected to it. The server stops at the end of the test
+func startTestServer(t *testing.T, s *DefaultServer, embedding DefaultMethodProvider) *testClient {
+	s.Init(embedding)
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	go func() {
+		s.Start(serverIn, serverOut)
+		serverOut.Close()
+	}()
+	c := testClient{
+		t:        t,
+		stream:   jsonrpc2.NewStream(clientIn, clientOut),
+		messages: make(chan testMessage, 100),
+	}
+	go func() {
+		defer close(c.messages)
+		for {
+			data, _, err := c.stream.Read()
+			if err != nil {
+				return
+			}
+			message := testMessage{}
+			if err := json.Unmarshal(data, &message); err == nil {
+				c.messages <- message
+			}
+		}
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+	return &c
+}
+
+func (c *testClient) send(message interface{}) {
+	c.t.Helper()
+	data, err := json.Marshal(message)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := c.stream.Write(data); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+//request sends a request with a numeric id
+func (c *testClient) request(id int64, method string, params interface{}) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+//respond answers a request sent by the server
+func (c *testClient) respond(id *jsonrpc2.ID, result interface{}) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+//next returns the next message sent by the server, failing the test if none arrives in time
+func (c *testClient) next() testMessage {
+	c.t.Helper()
+	select {
+	case message, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("server closed the connection")
+		}
+		return message
+	case <-time.After(2 * time.Second):
+		c.t.Fatal("timed out waiting for a message from the server")
+	}
+	return testMessage{}
+}
+
+//expectNone fails the test if the server sends a message within a short delay
+func (c *testClient) expectNone() {
+	c.t.Helper()
+	select {
+	case message := <-c.messages:
+		c.t.Fatalf("unexpected message from the server: %+v", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+//initialize performs the initialize handshake with the given parameters and returns the server capabilities by name
+func (c *testClient) initialize(params string) map[string]json.RawMessage {
+	c.t.Helper()
+	c.request(0, "initialize", json.RawMessage(params))
+	message := c.next()
+	result := struct {
+		Capabilities map[string]json.RawMessage `json:"capabilities"`
+	}{}
+	decode(c.t, message.Result, &result)
+	c.notify("initialized", struct{}{})
+	return result.Capabilities
+}
+
+//decode unmarshals JSON into `v`, failing the test on error
+func decode(t *testing.T, js json.RawMessage, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(js, v); err != nil {
+		t.Fatalf("cannot decode %s: %v", js, err)
+	}
+}
+
+//testServer is an embedding server without optional providers, recording the requests forwarded to it
+type testServer struct {
+	*DefaultServer
+	forwarded chan string
+}
+
+func newTestServer() *testServer {
+	return &testServer{DefaultServer: &DefaultServer{}, forwarded: make(chan string, 100)}
+}
+
+func (s *testServer) Default(req *jsonrpc2.Request) {
+	s.forwarded <- req.Method
+}
+
+func rng(startLine, startCharacter, endLine, endCharacter int64) code.Range {
+	return code.Range{
+		Start: code.Position{Line: startLine, Character: startCharacter},
+		End:   code.Position{Line: endLine, Character: endCharacter},
+	}
+}
+
+func TestCallMatchesResponseIDs(t *testing.T) {
+	s := newTestServer()
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{}}`)
+
+	results := make(chan string, 2)
+	for _, method := range []string{"test/numberID", "test/stringID"} {
+		method := method
+		go func() {
+			var result string
+			if err := s.Call(context.Background(), method, nil, &result); err != nil {
+				result = err.Error()
+			}
+			results <- result
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		request := c.next()
+		if request.Method == "test/stringID" {
+			c.respond(&jsonrpc2.ID{StringID: strconv.FormatInt(request.ID.NumberID, 10)}, "string")
+		} else {
+			c.respond(request.ID, "number")
+		}
+	}
+	got := []string{<-results, <-results}
+	sort.Strings(got)
+	if want := []string{"number", "string"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got results %q, want %q", got, want)
+	}
+}
+
+func TestMessagesFromOtherGoroutines(t *testing.T) {
+	s := newTestServer()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for s.LogMessage(MessageTypeInfo, "started") == errConnectionClosed {
+			_ = s.ClientCapabilities()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	c := startTestServer(t, s.DefaultServer, s)
+	<-done
+	if message := c.next(); message.Method != "window/logMessage" {
+		t.Errorf("got %+v, want a log message", message)
+	}
+}
+
+//expectForwarded fails the test unless `method` is forwarded to the embedding server, skipping methods forwarded before it
+func (s *testServer) expectForwarded(t *testing.T, method string) {
+	t.Helper()
+	for {
+		select {
+		case forwarded := <-s.forwarded:
+			if forwarded == method {
+				return
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s was not forwarded", method)
+		}
+	}
+}
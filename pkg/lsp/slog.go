@@ -0,0 +1,97 @@
+//go:build go1.21
+// +build go1.21
+
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+//LogHandler is a `slog.Handler` forwarding log records to the client with `window/logMessage`.
+//Records are sent as their message followed by their attributes in the `key=value` format of `slog.TextHandler`
+type LogHandler struct {
+	server *DefaultServer
+	level  slog.Leveler
+	text   slog.Handler
+	buffer *lockedBuffer
+}
+
+//lockedBuffer is the buffer the text handlers of a `LogHandler` and of those derived from it format records into
+type lockedBuffer struct {
+	sync.Mutex
+	bytes.Buffer
+}
+
+//NewLogHandler creates a handler forwarding the records of at least `level` to the client. A nil `level` means `slog.LevelInfo`
+func (s *DefaultServer) NewLogHandler(level slog.Leveler) *LogHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	buffer := &lockedBuffer{}
+	return &LogHandler{
+		server: s,
+		level:  level,
+		buffer: buffer,
+		text: slog.NewTextHandler(buffer, &slog.HandlerOptions{
+			Level: slog.LevelDebug - 100,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+	}
+}
+
+//Enabled reports whether records of `level` are forwarded to the client
+func (h *LogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+//Handle sends the record to the client, with the message type matching its level
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.buffer.Lock()
+	h.buffer.Reset()
+	err := h.text.Handle(ctx, r)
+	attrs := strings.TrimSpace(h.buffer.String())
+	h.buffer.Unlock()
+	if err != nil {
+		return err
+	}
+	message := r.Message
+	if attrs != "" {
+		message += " " + attrs
+	}
+	return h.server.LogMessage(messageTypeOf(r.Level), message)
+}
+
+//WithAttrs returns a handler adding `attrs` to all records
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *h
+	derived.text = h.text.WithAttrs(attrs)
+	return &derived
+}
+
+//WithGroup returns a handler qualifying the attributes of all records with the group `name`
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	derived := *h
+	derived.text = h.text.WithGroup(name)
+	return &derived
+}
+
+func messageTypeOf(level slog.Level) MessageType {
+	switch {
+	case level >= slog.LevelError:
+		return MessageTypeError
+	case level >= slog.LevelWarn:
+		return MessageTypeWarning
+	case level >= slog.LevelInfo:
+		return MessageTypeInfo
+	}
+	return MessageTypeLog
+}
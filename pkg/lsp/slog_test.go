@@ -0,0 +1,92 @@
+//go:build go1.21
+// +build go1.21
+
+package lsp
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+//expectLog fails the test unless the next message logs `message` with `messageType`
+func expectLog(c *testClient, messageType MessageType, message string) {
+	c.t.Helper()
+	next := c.next()
+	if next.Method != "window/logMessage" {
+		c.t.Fatalf("got %+v, want a log message", next)
+	}
+	params := LogMessageParams{}
+	decode(c.t, next.Params, &params)
+	if params.Type != messageType || params.Message != message {
+		c.t.Errorf("got log message %d %q, want %d %q", params.Type, params.Message, messageType, message)
+	}
+}
+
+func TestLogHandlerMessageTypes(t *testing.T) {
+	s := newTestServer()
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{}}`)
+	logger := slog.New(s.NewLogHandler(slog.LevelDebug - 4))
+	tests := []struct {
+		level slog.Level
+		want  MessageType
+	}{
+		{slog.LevelDebug - 4, MessageTypeLog},
+		{slog.LevelDebug, MessageTypeLog},
+		{slog.LevelInfo, MessageTypeInfo},
+		{slog.LevelInfo + 2, MessageTypeInfo},
+		{slog.LevelWarn, MessageTypeWarning},
+		{slog.LevelError, MessageTypeError},
+		{slog.LevelError + 4, MessageTypeError},
+	}
+	for _, tt := range tests {
+		logger.Log(context.Background(), tt.level, "message")
+		expectLog(c, tt.want, "message")
+	}
+}
+
+func TestLogHandlerFormatsAttributes(t *testing.T) {
+	s := newTestServer()
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{}}`)
+	logger := slog.New(s.NewLogHandler(nil))
+
+	logger.Info("plain")
+	expectLog(c, MessageTypeInfo, "plain")
+	logger.Info("attributes", "n", 1, "text", "two words")
+	expectLog(c, MessageTypeInfo, `attributes n=1 text="two words"`)
+	logger.Warn("group", slog.Group("request", "id", 7, "method", "initialize"))
+	expectLog(c, MessageTypeWarning, "group request.id=7 request.method=initialize")
+
+	derived := logger.With("server", "dsl").WithGroup("document").With("uri", "file:///a.dsl")
+	derived.Error("derived", "line", 3)
+	expectLog(c, MessageTypeError, "derived server=dsl document.uri=file:///a.dsl document.line=3")
+	//deriving a logger leaves the original one unchanged
+	logger.Info("original", "n", 2)
+	expectLog(c, MessageTypeInfo, "original n=2")
+}
+
+func TestLogHandlerEnabled(t *testing.T) {
+	s := newTestServer()
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{}}`)
+
+	if h := s.NewLogHandler(nil); h.Enabled(context.Background(), slog.LevelDebug) || !h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Errorf("a handler without a level should forward info records but not debug ones")
+	}
+	level := &slog.LevelVar{}
+	level.Set(slog.LevelWarn)
+	logger := slog.New(s.NewLogHandler(level))
+	logger.Info("filtered")
+	c.expectNone()
+	logger.Warn("forwarded")
+	expectLog(c, MessageTypeWarning, "forwarded")
+
+	level.Set(slog.LevelDebug)
+	logger.Debug("now forwarded")
+	expectLog(c, MessageTypeLog, "now forwarded")
+	if !logger.Handler().WithGroup("g").Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("a derived handler should follow the level of its parent")
+	}
+}
@@ -0,0 +1,42 @@
+package lsp
+
+//SymbolKind is the kind of a symbol
+type SymbolKind int
+
+//The kinds of symbols defined by the LSP
+const (
+	SymbolKindFile SymbolKind = iota + 1
+	SymbolKindModule
+	SymbolKindNamespace
+	SymbolKindPackage
+	SymbolKindClass
+	SymbolKindMethod
+	SymbolKindProperty
+	SymbolKindField
+	SymbolKindConstructor
+	SymbolKindEnum
+	SymbolKindInterface
+	SymbolKindFunction
+	SymbolKindVariable
+	SymbolKindConstant
+	SymbolKindString
+	SymbolKindNumber
+	SymbolKindBoolean
+	SymbolKindArray
+	SymbolKindObject
+	SymbolKindKey
+	SymbolKindNull
+	SymbolKindEnumMember
+	SymbolKindStruct
+	SymbolKindEvent
+	SymbolKindOperator
+	SymbolKindTypeParameter
+)
+
+//SymbolTag is an extra annotation that tweaks the rendering of a symbol
+type SymbolTag int
+
+const (
+	//SymbolTagDeprecated renders a symbol as obsolete, usually using a strike-out
+	SymbolTagDeprecated SymbolTag = 1
+)
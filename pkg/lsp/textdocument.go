@@ -53,11 +53,25 @@ type DidChangeTextDocumentParams struct {
 	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
 }
 
+//DidCloseTextDocumentParams are the parameters of a `textDocument/didClose` notification
+type DidCloseTextDocumentParams struct {
+	//The document that was closed
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
 //TextDocumentIdentifier identifies a text document
 type TextDocumentIdentifier struct {
 	URI code.DocumentURI `json:"uri"`
 }
 
+//TextDocumentPositionParams is a parameter literal used in requests to pass a text document and a position inside that document
+type TextDocumentPositionParams struct {
+	//The text document
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	//The position inside the text document
+	Position code.Position `json:"position"`
+}
+
 //VersionedTextDocumentIdentifier is The version number of this document. If a versioned text document identifier
 /** is sent from the server to the client and the file is not open in the editor
  * (the server has not received an open notification before) the server can send
@@ -78,3 +92,32 @@ type TextDocumentContentChangeEvent struct {
 	Range code.Range `json:"range,omitempty"`
 	Text  string     `json:"text"`
 }
+
+//TextEdit is a textual edit applicable to a text document. Edits within the same document must not overlap
+type TextEdit struct {
+	//The range of the text document to be manipulated. To insert text into a document create a range where start === end.
+	Range code.Range `json:"range"`
+
+	//The string to be inserted. For delete operations use an empty string.
+	NewText string `json:"newText"`
+
+	//AnnotationID makes this an annotated text edit, referring to a change annotation of the enclosing workspace edit.
+	//Annotations are only honoured in the document changes of a workspace edit
+	AnnotationID *ChangeAnnotationIdentifier `json:"annotationId,omitempty"`
+}
+
+//The markup kinds of `MarkupContent`
+const (
+	//MarkupKindPlainText is plain text
+	MarkupKindPlainText markupKind = "plaintext"
+	//MarkupKindMarkdown is Markdown
+	MarkupKindMarkdown markupKind = "markdown"
+)
+
+//MarkupContent is text in one of the markup kinds, rendered by the client according to its kind
+type MarkupContent struct {
+	//Kind of the markup, `MarkupKindPlainText` or `MarkupKindMarkdown`
+	Kind markupKind `json:"kind"`
+	//Value is the content itself
+	Value string `json:"value"`
+}
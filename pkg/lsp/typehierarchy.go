@@ -0,0 +1,136 @@
+package lsp
+
+import (
+	"encoding/json"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//TypeHierarchyPrepareParams are the parameters of a `textDocument/prepareTypeHierarchy` request
+type TypeHierarchyPrepareParams struct {
+	TextDocumentPositionParams
+}
+
+//TypeHierarchyItem is a node of the type hierarchy, such as a class or interface
+type TypeHierarchyItem struct {
+	//Name of this item
+	Name string `json:"name"`
+	//Kind of this item
+	Kind SymbolKind `json:"kind"`
+	//Tags for this item
+	Tags []SymbolTag `json:"tags,omitempty"`
+	//Detail of this type, e.g. its type parameters or the package it is declared in
+	Detail *string `json:"detail,omitempty"`
+	//URI is the resource identifier of this item
+	URI code.DocumentURI `json:"uri"`
+	//Range enclosing this symbol not including leading/trailing whitespace but everything else, e.g. comments and code
+	Range code.Range `json:"range"`
+	//SelectionRange is the range that should be selected and revealed when this symbol is being picked, e.g. the name of a type.
+	//Must be contained by `range`
+	SelectionRange code.Range `json:"selectionRange"`
+	//Data is preserved between a type hierarchy prepare and supertypes or subtypes requests
+	Data *json.RawMessage `json:"data,omitempty"`
+}
+
+//TypeHierarchySupertypesParams are the parameters of a `typeHierarchy/supertypes` request
+type TypeHierarchySupertypesParams struct {
+	Item TypeHierarchyItem `json:"item"`
+}
+
+//TypeHierarchySubtypesParams are the parameters of a `typeHierarchy/subtypes` request
+type TypeHierarchySubtypesParams struct {
+	Item TypeHierarchyItem `json:"item"`
+}
+
+//TypeHierarchyOptions are the server capabilities for type hierarchies
+type TypeHierarchyOptions struct {
+	*WorkDoneProgressOptions
+}
+
+//TypeHierarchyRegistrationOptions are the registration options for type hierarchies
+type TypeHierarchyRegistrationOptions struct {
+	*TextDocumentRegistrationOptions
+	*TypeHierarchyOptions
+	*StaticRegistrationOptions
+}
+
+type typeHierarchyUnion struct {
+	Boolean             *bool
+	Options             *TypeHierarchyOptions
+	RegistrationOptions *TypeHierarchyRegistrationOptions
+}
+
+func (tu *typeHierarchyUnion) MarshalJSON() ([]byte, error) {
+	if tu.Boolean != nil {
+		return json.Marshal(*tu.Boolean)
+	}
+	if tu.Options != nil {
+		return json.Marshal(*tu.Options)
+	}
+	return json.Marshal(tu.RegistrationOptions)
+}
+
+func (tu *typeHierarchyUnion) UnmarshalJSON(js []byte) error {
+	*tu = typeHierarchyUnion{}
+	var b bool
+	if err := json.Unmarshal(js, &b); err == nil {
+		tu.Boolean = &b
+		return nil
+	}
+	tu.RegistrationOptions = &TypeHierarchyRegistrationOptions{}
+	return json.Unmarshal(js, tu.RegistrationOptions)
+}
+
+//TypeHierarchyProvider is implemented by embedding servers that provide type hierarchies. The `Data` of the items returned by
+//`PrepareTypeHierarchy` is sent back unchanged with the items of the supertypes and subtypes requests
+type TypeHierarchyProvider interface {
+	PrepareTypeHierarchy(params *TypeHierarchyPrepareParams) ([]TypeHierarchyItem, error)
+	Supertypes(params *TypeHierarchySupertypesParams) ([]TypeHierarchyItem, error)
+	Subtypes(params *TypeHierarchySubtypesParams) ([]TypeHierarchyItem, error)
+}
+
+func (s *DefaultServer) prepareTypeHierarchy(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(TypeHierarchyProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := TypeHierarchyPrepareParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	items, err := provider.PrepareTypeHierarchy(&params)
+	s.reply(req, items, err)
+}
+
+func (s *DefaultServer) supertypes(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(TypeHierarchyProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := TypeHierarchySupertypesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	items, err := provider.Supertypes(&params)
+	s.reply(req, items, err)
+}
+
+func (s *DefaultServer) subtypes(req *jsonrpc2.Request) {
+	provider, ok := s.provider().(TypeHierarchyProvider)
+	if !ok {
+		s.forward(req)
+		return
+	}
+	params := TypeHierarchySubtypesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		s.SendErrorResponse(req.ID, err)
+		return
+	}
+	items, err := provider.Subtypes(&params)
+	s.reply(req, items, err)
+}
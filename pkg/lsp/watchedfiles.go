@@ -0,0 +1,261 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//FileChangeType is the type of a file event
+type FileChangeType int
+
+//The file change types
+const (
+	FileChangeTypeCreated FileChangeType = 1
+	FileChangeTypeChanged FileChangeType = 2
+	FileChangeTypeDeleted FileChangeType = 3
+)
+
+//FileEvent describes a change to a watched file
+type FileEvent struct {
+	//URI of the file
+	URI code.DocumentURI `json:"uri"`
+	//Type of the change
+	Type FileChangeType `json:"type"`
+}
+
+//DidChangeWatchedFilesParams are the parameters of a `workspace/didChangeWatchedFiles` notification
+type DidChangeWatchedFilesParams struct {
+	//Changes are the actual file events
+	Changes []FileEvent `json:"changes"`
+}
+
+//WatchKind is a bit set of the file changes a watcher is interested in
+type WatchKind int
+
+//The watch kinds
+const (
+	WatchKindCreate WatchKind = 1
+	WatchKindChange WatchKind = 2
+	WatchKindDelete WatchKind = 4
+	//WatchKindAll is the default kind of a watcher
+	WatchKindAll = WatchKindCreate | WatchKindChange | WatchKindDelete
+)
+
+//RelativePattern is a glob pattern matched against paths relative to a base URI, typically the URI of a workspace folder
+type RelativePattern struct {
+	//BaseURI to which the pattern is matched relatively
+	BaseURI code.DocumentURI `json:"baseUri"`
+	//Pattern is the glob pattern, see `Glob` for the syntax
+	Pattern string `json:"pattern"`
+}
+
+//GlobPattern is either a glob pattern matched against absolute paths, or a relative pattern if `Relative` is set
+type GlobPattern struct {
+	Pattern  string
+	Relative *RelativePattern
+}
+
+//NewRelativeGlobPattern creates a glob pattern matched relatively to the workspace folder
+func NewRelativeGlobPattern(folder WorkspaceFolder, pattern string) GlobPattern {
+	return GlobPattern{Relative: &RelativePattern{BaseURI: folder.URI, Pattern: pattern}}
+}
+
+func (gp GlobPattern) MarshalJSON() ([]byte, error) {
+	if gp.Relative != nil {
+		return json.Marshal(gp.Relative)
+	}
+	return json.Marshal(gp.Pattern)
+}
+
+func (gp *GlobPattern) UnmarshalJSON(js []byte) error {
+	*gp = GlobPattern{}
+	if err := json.Unmarshal(js, &gp.Pattern); err == nil {
+		return nil
+	}
+	gp.Relative = &RelativePattern{}
+	return json.Unmarshal(js, gp.Relative)
+}
+
+//FileSystemWatcher describes files to watch, and which of their changes are of interest
+type FileSystemWatcher struct {
+	//GlobPattern of the files to watch
+	GlobPattern GlobPattern `json:"globPattern"`
+	//Kind of events of interest. If missing it defaults to `WatchKindAll`
+	Kind *WatchKind `json:"kind,omitempty"`
+}
+
+//DidChangeWatchedFilesRegistrationOptions are the options to register for `workspace/didChangeWatchedFiles`
+type DidChangeWatchedFilesRegistrationOptions struct {
+	//Watchers to register
+	Watchers []FileSystemWatcher `json:"watchers"`
+}
+
+//WatchedFilesProvider is implemented by embedding servers interested in changes to files matching its watchers.
+//The watchers are registered with clients supporting dynamic registration once the client is initialized,
+//and only the events matching a watcher are passed to `DidChangeWatchedFiles`
+type WatchedFilesProvider interface {
+	FileSystemWatchers() []FileSystemWatcher
+	DidChangeWatchedFiles(events []FileEvent)
+}
+
+//compiledFileSystemWatcher is a file system watcher with its glob compiled, to match file events on the server
+type compiledFileSystemWatcher struct {
+	watcher FileSystemWatcher
+	base    string
+	glob    *Glob
+	kind    WatchKind
+}
+
+//compileFileSystemWatchers compiles the watchers of the embedding server, dropping those with invalid glob patterns
+func compileFileSystemWatchers(watchers []FileSystemWatcher) []compiledFileSystemWatcher {
+	compiled := []compiledFileSystemWatcher{}
+	for _, w := range watchers {
+		cw := compiledFileSystemWatcher{watcher: w, kind: WatchKindAll}
+		if w.Kind != nil {
+			cw.kind = *w.Kind
+		}
+		pattern := w.GlobPattern.Pattern
+		if w.GlobPattern.Relative != nil {
+			pattern = w.GlobPattern.Relative.Pattern
+			base, err := url.Parse(string(w.GlobPattern.Relative.BaseURI))
+			if err != nil {
+				continue
+			}
+			cw.base = strings.TrimSuffix(base.Path, "/") + "/"
+		}
+		glob, err := CompileGlob(pattern, false)
+		if err != nil {
+			continue
+		}
+		cw.glob = glob
+		compiled = append(compiled, cw)
+	}
+	return compiled
+}
+
+//matches reports whether the watcher is interested in the event
+func (cw compiledFileSystemWatcher) matches(event FileEvent) bool {
+	if cw.kind&watchKindOf(event.Type) == 0 {
+		return false
+	}
+	u, err := url.Parse(string(event.URI))
+	if err != nil {
+		return false
+	}
+	path := u.Path
+	if cw.base != "" {
+		if !strings.HasPrefix(path, cw.base) {
+			return false
+		}
+		path = strings.TrimPrefix(path, cw.base)
+	}
+	return cw.glob.Match(path)
+}
+
+//mayMatchUnder reports whether the watcher may be interested in files inside the directory whose URI has path `dir`
+func (cw compiledFileSystemWatcher) mayMatchUnder(dir string) bool {
+	if cw.base == "" {
+		return cw.glob.MayMatchUnder(dir)
+	}
+	dir = strings.TrimSuffix(dir, "/") + "/"
+	if strings.HasPrefix(cw.base, dir) {
+		//the directory holds the base of the pattern
+		return true
+	}
+	if !strings.HasPrefix(dir, cw.base) {
+		return false
+	}
+	return cw.glob.MayMatchUnder(strings.TrimSuffix(strings.TrimPrefix(dir, cw.base), "/"))
+}
+
+func watchKindOf(t FileChangeType) WatchKind {
+	switch t {
+	case FileChangeTypeCreated:
+		return WatchKindCreate
+	case FileChangeTypeChanged:
+		return WatchKindChange
+	case FileChangeTypeDeleted:
+		return WatchKindDelete
+	}
+	return 0
+}
+
+//clientWatchers returns the watchers to register with the client. Relative patterns are turned into absolute ones
+//for clients that do not support them
+func (s *DefaultServer) clientWatchers() []FileSystemWatcher {
+	relativeSupport := false
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc != nil && wc.DidChangeWatchedFiles != nil {
+		relativeSupport = wc.DidChangeWatchedFiles.RelativePatternSupport != nil && *wc.DidChangeWatchedFiles.RelativePatternSupport
+	}
+	watchers := []FileSystemWatcher{}
+	for _, cw := range s.fileWatchers {
+		w := cw.watcher
+		if w.GlobPattern.Relative != nil && !relativeSupport {
+			w.GlobPattern = GlobPattern{Pattern: EscapeGlob(cw.base) + w.GlobPattern.Relative.Pattern}
+		}
+		watchers = append(watchers, w)
+	}
+	return watchers
+}
+
+//canWatchFiles reports whether the client supports registering file watchers dynamically
+func (s *DefaultServer) canWatchFiles() bool {
+	wc := s.ClientCapabilities().WorkspaceCapabilities
+	return wc != nil && wc.DidChangeWatchedFiles != nil && wc.DidChangeWatchedFiles.DynamicRegistration != nil && *wc.DidChangeWatchedFiles.DynamicRegistration
+}
+
+//watchFiles compiles the watchers of the embedding server and registers them with the client, if it supports dynamic registration
+func (s *DefaultServer) watchFiles() {
+	provider, ok := s.provider().(WatchedFilesProvider)
+	if !ok {
+		return
+	}
+	s.fileWatchers = compileFileSystemWatchers(provider.FileSystemWatchers())
+	if len(s.fileWatchers) == 0 || !s.canWatchFiles() {
+		return
+	}
+	options := DidChangeWatchedFilesRegistrationOptions{Watchers: s.clientWatchers()}
+	go func() {
+		if _, err := s.RegisterCapability(context.Background(), "workspace/didChangeWatchedFiles", options); err != nil {
+			s.LogMessage(MessageTypeError, fmt.Sprintf("cannot register file watchers: %v", err))
+		}
+	}()
+}
+
+//dispatchFileEvents passes the events matching a watcher to the embedding server
+func (s *DefaultServer) dispatchFileEvents(events []FileEvent) {
+	provider, ok := s.provider().(WatchedFilesProvider)
+	if !ok {
+		return
+	}
+	matched := []FileEvent{}
+	for _, event := range events {
+		for _, cw := range s.fileWatchers {
+			if cw.matches(event) {
+				matched = append(matched, event)
+				break
+			}
+		}
+	}
+	if len(matched) > 0 {
+		provider.DidChangeWatchedFiles(matched)
+	}
+}
+
+func (s *DefaultServer) didChangeWatchedFiles(req *jsonrpc2.Request) {
+	if _, ok := s.provider().(WatchedFilesProvider); !ok {
+		s.forward(req)
+		return
+	}
+	params := DidChangeWatchedFilesParams{}
+	if err := decodeParams(req, &params); err != nil {
+		return
+	}
+	s.dispatchFileEvents(params.Changes)
+}
@@ -0,0 +1,60 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//watchedFilesServer watches files relative to a folder whose name has glob metacharacters
+type watchedFilesServer struct {
+	*testServer
+	events chan []FileEvent
+}
+
+func (s *watchedFilesServer) FileSystemWatchers() []FileSystemWatcher {
+	return []FileSystemWatcher{{GlobPattern: NewRelativeGlobPattern(WorkspaceFolder{URI: "file:///ws/a%5B1%5D%7Bx%7D", Name: "ws"}, "**/*.dsl")}}
+}
+
+func (s *watchedFilesServer) DidChangeWatchedFiles(events []FileEvent) {
+	s.events <- events
+}
+
+func TestWatchFilesEscapesRelativeBase(t *testing.T) {
+	s := &watchedFilesServer{testServer: newTestServer(), events: make(chan []FileEvent, 10)}
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{"workspace":{"didChangeWatchedFiles":{"dynamicRegistration":true}}}}`)
+	request := c.next()
+	if request.Method != "client/registerCapability" {
+		t.Fatalf("got %+v, want a registration", request)
+	}
+	params := struct {
+		Registrations []struct {
+			RegisterOptions struct {
+				Watchers []struct {
+					GlobPattern string `json:"globPattern"`
+				} `json:"watchers"`
+			} `json:"registerOptions"`
+		} `json:"registrations"`
+	}{}
+	decode(t, request.Params, &params)
+	pattern := params.Registrations[0].RegisterOptions.Watchers[0].GlobPattern
+	if want := "/ws/a[[]1][{]x[}]/**/*.dsl"; pattern != want {
+		t.Errorf("registered pattern %q, want %q", pattern, want)
+	}
+
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "error": jsonrpc2.Error{Code: jsonrpc2.CodeInternalError, Message: "no watching today"}})
+	log := c.next()
+	if log.Method != "window/logMessage" || !strings.Contains(string(log.Params), "no watching today") {
+		t.Errorf("got %+v, want the registration failure logged", log)
+	}
+
+	c.notify("workspace/didChangeWatchedFiles", DidChangeWatchedFilesParams{Changes: []FileEvent{
+		{URI: "file:///ws/a%5B1%5D%7Bx%7D/lib/b.dsl", Type: FileChangeTypeChanged},
+		{URI: "file:///ws/a1x/lib/b.dsl", Type: FileChangeTypeChanged},
+	}})
+	if events := <-s.events; len(events) != 1 || events[0].URI != "file:///ws/a%5B1%5D%7Bx%7D/lib/b.dsl" {
+		t.Errorf("got events %v, want only the event under the watched folder", events)
+	}
+}
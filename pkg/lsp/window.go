@@ -0,0 +1,80 @@
+package lsp
+
+import (
+	"context"
+)
+
+//MessageType is the type of a message shown or logged by the client
+type MessageType int
+
+//The message types
+const (
+	MessageTypeError   MessageType = 1
+	MessageTypeWarning MessageType = 2
+	MessageTypeInfo    MessageType = 3
+	MessageTypeLog     MessageType = 4
+)
+
+//ShowMessageParams are the parameters of a `window/showMessage` notification
+type ShowMessageParams struct {
+	//Type of the message
+	Type MessageType `json:"type"`
+	//Message is the actual message
+	Message string `json:"message"`
+}
+
+//MessageActionItem is an action the user can choose in response to a `window/showMessageRequest`
+type MessageActionItem struct {
+	//Title is a short title like 'Retry', 'Open Log' etc.
+	Title string `json:"title"`
+}
+
+//ShowMessageRequestParams are the parameters of a `window/showMessageRequest` request
+type ShowMessageRequestParams struct {
+	//Type of the message
+	Type MessageType `json:"type"`
+	//Message is the actual message
+	Message string `json:"message"`
+	//Actions are the message action items to present
+	Actions []MessageActionItem `json:"actions,omitempty"`
+}
+
+//LogMessageParams are the parameters of a `window/logMessage` notification
+type LogMessageParams struct {
+	//Type of the message
+	Type MessageType `json:"type"`
+	//Message is the actual message
+	Message string `json:"message"`
+}
+
+//ShowMessage asks the client to display a message to the user with `window/showMessage`
+func (s *DefaultServer) ShowMessage(messageType MessageType, message string) error {
+	transport := s.transport()
+	if transport == nil {
+		return errConnectionClosed
+	}
+	return transport.SendNotification("window/showMessage", ShowMessageParams{Type: messageType, Message: message})
+}
+
+//ShowMessageRequest asks the client to display a message to the user with `window/showMessageRequest`, and returns the action the user chose,
+//or nil if the message was dismissed. Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) ShowMessageRequest(ctx context.Context, messageType MessageType, message string, actions ...string) (*MessageActionItem, error) {
+	params := ShowMessageRequestParams{Type: messageType, Message: message}
+	for _, action := range actions {
+		params.Actions = append(params.Actions, MessageActionItem{Title: action})
+	}
+	var chosen *MessageActionItem
+	if err := s.Call(ctx, "window/showMessageRequest", params, &chosen); err != nil {
+		return nil, err
+	}
+	return chosen, nil
+}
+
+//LogMessage asks the client to log a message, usually in its output panel, with `window/logMessage`
+func (s *DefaultServer) LogMessage(messageType MessageType, message string) error {
+	transport := s.transport()
+	if transport == nil {
+		return errConnectionClosed
+	}
+	return transport.SendNotification("window/logMessage", LogMessageParams{Type: messageType, Message: message})
+}
@@ -0,0 +1,198 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+	"github.com/adedayo/go-lsp/pkg/jsonrpc2"
+)
+
+//WorkspaceFoldersChangeEvent describes the workspace folders added and removed
+type WorkspaceFoldersChangeEvent struct {
+	//Added workspace folders
+	Added []WorkspaceFolder `json:"added"`
+	//Removed workspace folders
+	Removed []WorkspaceFolder `json:"removed"`
+}
+
+//DidChangeWorkspaceFoldersParams are the parameters of a `workspace/didChangeWorkspaceFolders` notification
+type DidChangeWorkspaceFoldersParams struct {
+	//Event is the actual workspace folder change event
+	Event WorkspaceFoldersChangeEvent `json:"event"`
+}
+
+//WorkspaceFoldersListener is optionally implemented by embedding servers to be told about workspace folders changes,
+//after the server's `Workspace` has been updated
+type WorkspaceFoldersListener interface {
+	DidChangeWorkspaceFolders(event WorkspaceFoldersChangeEvent)
+}
+
+//Workspace tracks the workspace folders open in the client. It is safe for concurrent use
+type Workspace struct {
+	mutex   sync.RWMutex
+	folders []WorkspaceFolder
+}
+
+//NewWorkspace creates a workspace with the given folders
+func NewWorkspace(folders ...WorkspaceFolder) *Workspace {
+	w := Workspace{}
+	w.Add(folders...)
+	return &w
+}
+
+//Folders returns the current workspace folders
+func (w *Workspace) Folders() []WorkspaceFolder {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	folders := make([]WorkspaceFolder, len(w.folders))
+	copy(folders, w.folders)
+	return folders
+}
+
+//Add adds folders to the workspace, ignoring those already part of it
+func (w *Workspace) Add(folders ...WorkspaceFolder) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.add(folders)
+}
+
+//Remove removes the folders with the given URIs from the workspace
+func (w *Workspace) Remove(uris ...code.DocumentURI) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.remove(uris)
+}
+
+//SetFolders replaces the folders of the workspace
+func (w *Workspace) SetFolders(folders []WorkspaceFolder) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.folders = nil
+	w.add(folders)
+}
+
+//Apply updates the workspace with a change event, removing folders before adding new ones.
+//Readers see the folders either before or after the whole change
+func (w *Workspace) Apply(event WorkspaceFoldersChangeEvent) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, folder := range event.Removed {
+		w.remove([]code.DocumentURI{folder.URI})
+	}
+	w.add(event.Added)
+}
+
+func (w *Workspace) add(folders []WorkspaceFolder) {
+	for _, folder := range folders {
+		if w.indexOf(folder.URI) < 0 {
+			w.folders = append(w.folders, folder)
+		}
+	}
+}
+
+func (w *Workspace) remove(uris []code.DocumentURI) {
+	for _, uri := range uris {
+		if i := w.indexOf(uri); i >= 0 {
+			w.folders = append(w.folders[:i:i], w.folders[i+1:]...)
+		}
+	}
+}
+
+func (w *Workspace) indexOf(uri code.DocumentURI) int {
+	for i, folder := range w.folders {
+		if sameURI(folder.URI, uri) {
+			return i
+		}
+	}
+	return -1
+}
+
+//FolderOf returns the workspace folder containing `uri`. With nested folders, the innermost one is returned
+func (w *Workspace) FolderOf(uri code.DocumentURI) (WorkspaceFolder, bool) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	owner, length := WorkspaceFolder{}, -1
+	for _, folder := range w.folders {
+		if n := containedIn(uri, folder.URI); n > length {
+			owner, length = folder, n
+		}
+	}
+	return owner, length >= 0
+}
+
+//containedIn returns the length of the path of `folder` if it contains `uri`, and -1 otherwise
+func containedIn(uri, folder code.DocumentURI) int {
+	u, err := url.Parse(string(uri))
+	if err != nil {
+		return -1
+	}
+	f, err := url.Parse(string(folder))
+	if err != nil || u.Scheme != f.Scheme || u.Host != f.Host {
+		return -1
+	}
+	folderPath := strings.TrimSuffix(f.Path, "/")
+	if u.Path != folderPath && !strings.HasPrefix(u.Path, folderPath+"/") {
+		return -1
+	}
+	return len(folderPath)
+}
+
+func sameURI(a, b code.DocumentURI) bool {
+	return a == b || (containedIn(a, b) >= 0 && containedIn(b, a) >= 0)
+}
+
+//initialWorkspaceFolders returns the workspace folders of the initialize request, or a folder for the root URI if the client sent none
+func initialWorkspaceFolders(params InitializeParams) []WorkspaceFolder {
+	if len(params.WorkspaceFolders) > 0 || params.RootURI == nil {
+		return params.WorkspaceFolders
+	}
+	root := *params.RootURI
+	name := root
+	if u, err := url.Parse(string(root)); err == nil {
+		name = code.DocumentURI(path.Base(u.Path))
+	}
+	return []WorkspaceFolder{{URI: root, Name: name}}
+}
+
+//Workspace returns the workspace folders the client has open, kept up to date with `workspace/didChangeWorkspaceFolders`
+func (s *DefaultServer) Workspace() *Workspace {
+	s.workspaceOnce.Do(func() {
+		s.workspace = NewWorkspace()
+	})
+	return s.workspace
+}
+
+func (s *DefaultServer) didChangeWorkspaceFolders(req *jsonrpc2.Request) {
+	params := DidChangeWorkspaceFoldersParams{}
+	if err := decodeParams(req, &params); err != nil {
+		return
+	}
+	s.Workspace().Apply(params.Event)
+	if listener, ok := s.provider().(WorkspaceFoldersListener); ok {
+		listener.DidChangeWorkspaceFolders(params.Event)
+		return
+	}
+	s.forward(req)
+}
+
+//errWorkspaceFoldersUnsupported is returned when the client did not declare support for workspace folders
+var errWorkspaceFoldersUnsupported = errors.New("client does not support workspace folders")
+
+//RequestWorkspaceFolders fetches the current workspace folders with `workspace/workspaceFolders` and updates the server's `Workspace` with them.
+//It returns nil, leaving the workspace without folders, if only a single file is open. Like `Call`, it must not be invoked from the `Start` loop
+func (s *DefaultServer) RequestWorkspaceFolders(ctx context.Context) ([]WorkspaceFolder, error) {
+	if wc := s.ClientCapabilities().WorkspaceCapabilities; wc == nil || wc.WorkspaceFolders == nil || !*wc.WorkspaceFolders {
+		return nil, errWorkspaceFoldersUnsupported
+	}
+	var folders []WorkspaceFolder
+	if err := s.Call(ctx, "workspace/workspaceFolders", nil, &folders); err != nil {
+		return nil, err
+	}
+	s.Workspace().SetFolders(folders)
+	return folders, nil
+}
@@ -0,0 +1,105 @@
+package lsp
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+func folders(names ...string) []WorkspaceFolder {
+	result := []WorkspaceFolder{}
+	for _, name := range names {
+		result = append(result, WorkspaceFolder{URI: code.DocumentURI("file:///ws/" + name), Name: code.DocumentURI(name)})
+	}
+	return result
+}
+
+func TestWorkspaceChanges(t *testing.T) {
+	w := NewWorkspace(folders("a", "b", "a")...)
+	if got, want := w.Folders(), folders("a", "b"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	w.Apply(WorkspaceFoldersChangeEvent{Added: folders("c", "a"), Removed: folders("a")})
+	if got, want := w.Folders(), folders("b", "c", "a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v after apply, want %v", got, want)
+	}
+	w.Remove("file:///ws/c", "file:///ws/missing")
+	if got, want := w.Folders(), folders("b", "a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v after remove, want %v", got, want)
+	}
+	w.SetFolders(folders("d"))
+	if got, want := w.Folders(), folders("d"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v after set, want %v", got, want)
+	}
+}
+
+func TestWorkspaceChangesAreAtomic(t *testing.T) {
+	w := NewWorkspace(folders("a")...)
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if got := w.Folders(); len(got) != 1 {
+				t.Errorf("observed %v in the middle of a change", got)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		if i%2 == 0 {
+			w.SetFolders(folders("b"))
+		} else {
+			w.Apply(WorkspaceFoldersChangeEvent{Added: folders("a"), Removed: folders("b")})
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestRequestWorkspaceFolders(t *testing.T) {
+	s := newTestServer()
+	c := startTestServer(t, s.DefaultServer, s)
+	c.initialize(`{"capabilities":{"workspace":{"workspaceFolders":true}},"workspaceFolders":[{"uri":"file:///ws/a","name":"a"}]}`)
+	type result struct {
+		folders []WorkspaceFolder
+		err     error
+	}
+	request := func(answer interface{}) result {
+		t.Helper()
+		results := make(chan result, 1)
+		go func() {
+			folders, err := s.RequestWorkspaceFolders(context.Background())
+			results <- result{folders, err}
+		}()
+		message := c.next()
+		if message.Method != "workspace/workspaceFolders" {
+			t.Fatalf("got %+v, want a workspace folders request", message)
+		}
+		c.respond(message.ID, answer)
+		return <-results
+	}
+
+	if got := request(folders("b", "c")); got.err != nil || !reflect.DeepEqual(got.folders, folders("b", "c")) {
+		t.Errorf("got %v, %v, want %v", got.folders, got.err, folders("b", "c"))
+	}
+	if got, want := s.Workspace().Folders(), folders("b", "c"); !reflect.DeepEqual(got, want) {
+		t.Errorf("workspace has %v, want %v", got, want)
+	}
+
+	if got := request(nil); got.err != nil || got.folders != nil {
+		t.Errorf("got %#v, %v for a null result, want nil", got.folders, got.err)
+	}
+	if got := s.Workspace().Folders(); len(got) != 0 {
+		t.Errorf("workspace has %v with only a single file open", got)
+	}
+}
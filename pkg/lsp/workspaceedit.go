@@ -0,0 +1,464 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+//ResourceOperationKind is the kind of resource operations supported by the client
+type ResourceOperationKind string
+
+const (
+	//ResourceOperationCreate supports creating new files and folders
+	ResourceOperationCreate ResourceOperationKind = "create"
+	//ResourceOperationRename supports renaming existing files and folders
+	ResourceOperationRename ResourceOperationKind = "rename"
+	//ResourceOperationDelete supports deleting existing files and folders
+	ResourceOperationDelete ResourceOperationKind = "delete"
+)
+
+//FailureHandlingKind describes how the client handles failures when applying a workspace edit
+type FailureHandlingKind string
+
+const (
+	//FailureHandlingAbort means applying the workspace change is simply aborted if one of the changes provided fails.
+	//All operations executed before the failing operation stay executed
+	FailureHandlingAbort FailureHandlingKind = "abort"
+	//FailureHandlingTransactional means all operations are executed transactionally. That means they either all succeed or no changes at all are applied to the workspace
+	FailureHandlingTransactional FailureHandlingKind = "transactional"
+	//FailureHandlingTextOnlyTransactional means textual file changes are executed transactionally, while resource changes (create, rename, delete file) are not
+	FailureHandlingTextOnlyTransactional FailureHandlingKind = "textOnlyTransactional"
+	//FailureHandlingUndo means the client tries to undo the operations already executed, but there is no guarantee that this succeeds
+	FailureHandlingUndo FailureHandlingKind = "undo"
+)
+
+//WorkspaceEdit represents changes to many resources managed in the workspace.
+//Only one of `Changes` or `DocumentChanges` is used, depending on whether the client supports document changes
+type WorkspaceEdit struct {
+	//Changes holds changes to existing resources
+	Changes map[code.DocumentURI][]TextEdit `json:"changes,omitempty"`
+	//DocumentChanges are an ordered list of versioned text document edits and resource operations
+	DocumentChanges []DocumentChange `json:"documentChanges,omitempty"`
+	//ChangeAnnotations describe the annotated edits and resource operations of the document changes, keyed by identifier
+	ChangeAnnotations map[ChangeAnnotationIdentifier]ChangeAnnotation `json:"changeAnnotations,omitempty"`
+}
+
+//ChangeAnnotationIdentifier identifies a change annotation managed by a workspace edit
+type ChangeAnnotationIdentifier string
+
+//ChangeAnnotation gives additional information about a change, for instance to have the user confirm it before it is applied
+type ChangeAnnotation struct {
+	//Label is a human-readable string describing the actual change. The string is rendered prominent in the user interface
+	Label string `json:"label"`
+	//NeedsConfirmation signals that user confirmation is needed before applying the change
+	NeedsConfirmation *bool `json:"needsConfirmation,omitempty"`
+	//Description is a human-readable string which is rendered less prominent in the user interface
+	Description *string `json:"description,omitempty"`
+}
+
+//TextDocumentEdit describes textual changes on a single text document. The version is that of the document the edits were computed against
+type TextDocumentEdit struct {
+	TextDocument VersionedTextDocumentIdentifier `json:"textDocument"`
+	Edits        []TextEdit                      `json:"edits"`
+}
+
+//CreateFileOptions are the options to create a file
+type CreateFileOptions struct {
+	//Overwrite existing file. Overwrite wins over `ignoreIfExists`
+	Overwrite *bool `json:"overwrite,omitempty"`
+	//IgnoreIfExists ignores the operation if the file already exists
+	IgnoreIfExists *bool `json:"ignoreIfExists,omitempty"`
+}
+
+//CreateFile is an operation to create a file
+type CreateFile struct {
+	Kind         ResourceOperationKind       `json:"kind"`
+	URI          code.DocumentURI            `json:"uri"`
+	Options      *CreateFileOptions          `json:"options,omitempty"`
+	AnnotationID *ChangeAnnotationIdentifier `json:"annotationId,omitempty"`
+}
+
+//RenameFileOptions are the options to rename a file
+type RenameFileOptions struct {
+	//Overwrite target if existing. Overwrite wins over `ignoreIfExists`
+	Overwrite *bool `json:"overwrite,omitempty"`
+	//IgnoreIfExists ignores the operation if the target exists
+	IgnoreIfExists *bool `json:"ignoreIfExists,omitempty"`
+}
+
+//RenameFile is an operation to rename a file
+type RenameFile struct {
+	Kind         ResourceOperationKind       `json:"kind"`
+	OldURI       code.DocumentURI            `json:"oldUri"`
+	NewURI       code.DocumentURI            `json:"newUri"`
+	Options      *RenameFileOptions          `json:"options,omitempty"`
+	AnnotationID *ChangeAnnotationIdentifier `json:"annotationId,omitempty"`
+}
+
+//DeleteFileOptions are the options to delete a file
+type DeleteFileOptions struct {
+	//Recursive deletes the content recursively if a folder is denoted
+	Recursive *bool `json:"recursive,omitempty"`
+	//IgnoreIfNotExists ignores the operation if the file doesn't exist
+	IgnoreIfNotExists *bool `json:"ignoreIfNotExists,omitempty"`
+}
+
+//DeleteFile is an operation to delete a file
+type DeleteFile struct {
+	Kind         ResourceOperationKind       `json:"kind"`
+	URI          code.DocumentURI            `json:"uri"`
+	Options      *DeleteFileOptions          `json:"options,omitempty"`
+	AnnotationID *ChangeAnnotationIdentifier `json:"annotationId,omitempty"`
+}
+
+//DocumentChange is one entry of `WorkspaceEdit.DocumentChanges`: exactly one of its fields is set
+type DocumentChange struct {
+	TextDocumentEdit *TextDocumentEdit
+	CreateFile       *CreateFile
+	RenameFile       *RenameFile
+	DeleteFile       *DeleteFile
+}
+
+//MarshalJSON encodes whichever of the document change variants is set
+func (dc *DocumentChange) MarshalJSON() ([]byte, error) {
+	switch {
+	case dc.CreateFile != nil:
+		return json.Marshal(dc.CreateFile)
+	case dc.RenameFile != nil:
+		return json.Marshal(dc.RenameFile)
+	case dc.DeleteFile != nil:
+		return json.Marshal(dc.DeleteFile)
+	}
+	return json.Marshal(dc.TextDocumentEdit)
+}
+
+//UnmarshalJSON decodes a document change, using the `kind` property to tell resource operations from text document edits
+func (dc *DocumentChange) UnmarshalJSON(js []byte) error {
+	*dc = DocumentChange{}
+	var kind struct {
+		Kind ResourceOperationKind `json:"kind"`
+	}
+	if err := json.Unmarshal(js, &kind); err != nil {
+		return err
+	}
+	switch kind.Kind {
+	case ResourceOperationCreate:
+		dc.CreateFile = &CreateFile{}
+		return json.Unmarshal(js, dc.CreateFile)
+	case ResourceOperationRename:
+		dc.RenameFile = &RenameFile{}
+		return json.Unmarshal(js, dc.RenameFile)
+	case ResourceOperationDelete:
+		dc.DeleteFile = &DeleteFile{}
+		return json.Unmarshal(js, dc.DeleteFile)
+	}
+	dc.TextDocumentEdit = &TextDocumentEdit{}
+	return json.Unmarshal(js, dc.TextDocumentEdit)
+}
+
+//WorkspaceEditBuilder accumulates text edits and resource operations and builds a `WorkspaceEdit` suited to the
+//capabilities of the client. Errors are recorded as they occur and reported by `Build`
+type WorkspaceEditBuilder struct {
+	capabilities WorkspaceEditClientCapabilities
+	changes      []DocumentChange
+	//open maps a document to the index of the text document edit collecting its edits since the last resource operation on it
+	open map[code.DocumentURI]int
+	//gone holds documents that have been deleted or renamed away and can no longer be edited
+	gone        map[code.DocumentURI]ResourceOperationKind
+	annotations map[ChangeAnnotationIdentifier]ChangeAnnotation
+	err         error
+}
+
+//NewWorkspaceEditBuilder creates a builder for the given client capabilities, which may be nil if the client declared none
+func NewWorkspaceEditBuilder(capabilities *WorkspaceEditClientCapabilities) *WorkspaceEditBuilder {
+	b := WorkspaceEditBuilder{
+		open: make(map[code.DocumentURI]int),
+		gone: make(map[code.DocumentURI]ResourceOperationKind),
+	}
+	if capabilities != nil {
+		b.capabilities = *capabilities
+	}
+	return &b
+}
+
+//Edit adds text edits to the document identified by `uri`, computed against document `version` (nil if unknown).
+//Edits made to a document between resource operations on it are merged into a single text document edit
+func (b *WorkspaceEditBuilder) Edit(uri code.DocumentURI, version *int64, edits ...TextEdit) *WorkspaceEditBuilder {
+	if b.err != nil {
+		return b
+	}
+	if kind, gone := b.gone[uri]; gone {
+		b.err = fmt.Errorf("cannot edit %s after a %s operation removed it", uri, kind)
+		return b
+	}
+	if index, ok := b.open[uri]; ok {
+		tde := b.changes[index].TextDocumentEdit
+		if !sameVersion(tde.TextDocument.Version, version) {
+			b.err = fmt.Errorf("conflicting versions for edits of %s", uri)
+			return b
+		}
+		tde.Edits = append(tde.Edits, edits...)
+		return b
+	}
+	b.open[uri] = len(b.changes)
+	b.changes = append(b.changes, DocumentChange{
+		TextDocumentEdit: &TextDocumentEdit{
+			TextDocument: VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: TextDocumentIdentifier{URI: uri},
+				Version:                version,
+			},
+			Edits: append([]TextEdit{}, edits...),
+		},
+	})
+	return b
+}
+
+//Annotate registers a change annotation that edits and resource operations can refer to with its `id`.
+//Annotations are dropped from the workspace edit if the client does not support them
+func (b *WorkspaceEditBuilder) Annotate(id ChangeAnnotationIdentifier, annotation ChangeAnnotation) *WorkspaceEditBuilder {
+	if b.annotations == nil {
+		b.annotations = make(map[ChangeAnnotationIdentifier]ChangeAnnotation)
+	}
+	b.annotations[id] = annotation
+	return b
+}
+
+//CreateFile adds an operation creating the file at `uri`. Subsequent edits of `uri` are applied to the new file
+func (b *WorkspaceEditBuilder) CreateFile(uri code.DocumentURI, options *CreateFileOptions) *WorkspaceEditBuilder {
+	return b.createFile(uri, options, nil)
+}
+
+//AnnotatedCreateFile is like `CreateFile`, with the operation annotated by the change annotation `id` registered with `Annotate`
+func (b *WorkspaceEditBuilder) AnnotatedCreateFile(uri code.DocumentURI, options *CreateFileOptions, id ChangeAnnotationIdentifier) *WorkspaceEditBuilder {
+	return b.createFile(uri, options, &id)
+}
+
+func (b *WorkspaceEditBuilder) createFile(uri code.DocumentURI, options *CreateFileOptions, id *ChangeAnnotationIdentifier) *WorkspaceEditBuilder {
+	if !b.supports(ResourceOperationCreate) {
+		return b
+	}
+	delete(b.gone, uri)
+	delete(b.open, uri)
+	b.changes = append(b.changes, DocumentChange{
+		CreateFile: &CreateFile{Kind: ResourceOperationCreate, URI: uri, Options: options, AnnotationID: id},
+	})
+	return b
+}
+
+//RenameFile adds an operation renaming the file at `oldURI` to `newURI`. Edits of `oldURI` made before the rename are applied before it,
+//while subsequent edits must target `newURI`
+func (b *WorkspaceEditBuilder) RenameFile(oldURI, newURI code.DocumentURI, options *RenameFileOptions) *WorkspaceEditBuilder {
+	return b.renameFile(oldURI, newURI, options, nil)
+}
+
+//AnnotatedRenameFile is like `RenameFile`, with the operation annotated by the change annotation `id` registered with `Annotate`
+func (b *WorkspaceEditBuilder) AnnotatedRenameFile(oldURI, newURI code.DocumentURI, options *RenameFileOptions, id ChangeAnnotationIdentifier) *WorkspaceEditBuilder {
+	return b.renameFile(oldURI, newURI, options, &id)
+}
+
+func (b *WorkspaceEditBuilder) renameFile(oldURI, newURI code.DocumentURI, options *RenameFileOptions, id *ChangeAnnotationIdentifier) *WorkspaceEditBuilder {
+	if !b.supports(ResourceOperationRename) {
+		return b
+	}
+	if kind, gone := b.gone[oldURI]; gone {
+		b.err = fmt.Errorf("cannot rename %s after a %s operation removed it", oldURI, kind)
+		return b
+	}
+	b.gone[oldURI] = ResourceOperationRename
+	delete(b.open, oldURI)
+	delete(b.gone, newURI)
+	delete(b.open, newURI)
+	b.changes = append(b.changes, DocumentChange{
+		RenameFile: &RenameFile{Kind: ResourceOperationRename, OldURI: oldURI, NewURI: newURI, Options: options, AnnotationID: id},
+	})
+	return b
+}
+
+//DeleteFile adds an operation deleting the file or folder at `uri`. The document may not be edited afterwards unless it is created again
+func (b *WorkspaceEditBuilder) DeleteFile(uri code.DocumentURI, options *DeleteFileOptions) *WorkspaceEditBuilder {
+	return b.deleteFile(uri, options, nil)
+}
+
+//AnnotatedDeleteFile is like `DeleteFile`, with the operation annotated by the change annotation `id` registered with `Annotate`
+func (b *WorkspaceEditBuilder) AnnotatedDeleteFile(uri code.DocumentURI, options *DeleteFileOptions, id ChangeAnnotationIdentifier) *WorkspaceEditBuilder {
+	return b.deleteFile(uri, options, &id)
+}
+
+func (b *WorkspaceEditBuilder) deleteFile(uri code.DocumentURI, options *DeleteFileOptions, id *ChangeAnnotationIdentifier) *WorkspaceEditBuilder {
+	if !b.supports(ResourceOperationDelete) {
+		return b
+	}
+	b.gone[uri] = ResourceOperationDelete
+	delete(b.open, uri)
+	b.changes = append(b.changes, DocumentChange{
+		DeleteFile: &DeleteFile{Kind: ResourceOperationDelete, URI: uri, Options: options, AnnotationID: id},
+	})
+	return b
+}
+
+//Build validates the accumulated changes and returns the workspace edit. Document changes are emitted if the client supports them,
+//otherwise the text edits are grouped by document in `changes`, which cannot express resource operations or versions.
+//The edit is a copy of the accumulated changes, so the builder can be built again or extended afterwards
+func (b *WorkspaceEditBuilder) Build() (*WorkspaceEdit, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	for _, change := range b.changes {
+		if tde := change.TextDocumentEdit; tde != nil {
+			if err := validateEdits(tde.Edits); err != nil {
+				return nil, fmt.Errorf("invalid edits for %s: %v", tde.TextDocument.URI, err)
+			}
+		}
+	}
+	if err := b.checkAnnotations(); err != nil {
+		return nil, err
+	}
+	documentChanges := b.capabilities.DocumentChanges != nil && *b.capabilities.DocumentChanges
+	annotated := documentChanges && b.capabilities.ChangeAnnotationSupport != nil
+	changes := copyDocumentChanges(b.changes, annotated)
+	if documentChanges {
+		edit := WorkspaceEdit{DocumentChanges: changes}
+		if annotated && len(b.annotations) > 0 {
+			edit.ChangeAnnotations = make(map[ChangeAnnotationIdentifier]ChangeAnnotation, len(b.annotations))
+			for id, annotation := range b.annotations {
+				edit.ChangeAnnotations[id] = annotation
+			}
+		}
+		return &edit, nil
+	}
+	edit := WorkspaceEdit{Changes: make(map[code.DocumentURI][]TextEdit)}
+	for _, change := range changes {
+		tde := change.TextDocumentEdit
+		if tde == nil {
+			return nil, fmt.Errorf("client does not support resource operations without document changes")
+		}
+		uri := tde.TextDocument.URI
+		if _, present := edit.Changes[uri]; present {
+			return nil, fmt.Errorf("edits for %s are interleaved with resource operations, which requires document changes support", uri)
+		}
+		edit.Changes[uri] = tde.Edits
+	}
+	return &edit, nil
+}
+
+//checkAnnotations verifies that the annotations referred to by the changes have been registered
+func (b *WorkspaceEditBuilder) checkAnnotations() error {
+	for _, change := range b.changes {
+		ids := []*ChangeAnnotationIdentifier{}
+		switch {
+		case change.TextDocumentEdit != nil:
+			for _, edit := range change.TextDocumentEdit.Edits {
+				ids = append(ids, edit.AnnotationID)
+			}
+		case change.CreateFile != nil:
+			ids = append(ids, change.CreateFile.AnnotationID)
+		case change.RenameFile != nil:
+			ids = append(ids, change.RenameFile.AnnotationID)
+		case change.DeleteFile != nil:
+			ids = append(ids, change.DeleteFile.AnnotationID)
+		}
+		for _, id := range ids {
+			if id == nil {
+				continue
+			}
+			if _, ok := b.annotations[*id]; !ok {
+				return fmt.Errorf("unknown change annotation %q", *id)
+			}
+		}
+	}
+	return nil
+}
+
+//copyDocumentChanges copies document changes, dropping their change annotations unless `annotated` is set
+func copyDocumentChanges(changes []DocumentChange, annotated bool) []DocumentChange {
+	strip := func(id *ChangeAnnotationIdentifier) *ChangeAnnotationIdentifier {
+		if annotated {
+			return id
+		}
+		return nil
+	}
+	copies := make([]DocumentChange, len(changes))
+	for i, change := range changes {
+		switch {
+		case change.TextDocumentEdit != nil:
+			tde := *change.TextDocumentEdit
+			tde.Edits = copyTextEdits(tde.Edits, annotated)
+			copies[i].TextDocumentEdit = &tde
+		case change.CreateFile != nil:
+			op := *change.CreateFile
+			op.AnnotationID = strip(op.AnnotationID)
+			copies[i].CreateFile = &op
+		case change.RenameFile != nil:
+			op := *change.RenameFile
+			op.AnnotationID = strip(op.AnnotationID)
+			copies[i].RenameFile = &op
+		case change.DeleteFile != nil:
+			op := *change.DeleteFile
+			op.AnnotationID = strip(op.AnnotationID)
+			copies[i].DeleteFile = &op
+		}
+	}
+	return copies
+}
+
+//copyTextEdits copies text edits, dropping their change annotations unless `annotated` is set
+func copyTextEdits(edits []TextEdit, annotated bool) []TextEdit {
+	copies := append([]TextEdit{}, edits...)
+	if !annotated {
+		for i := range copies {
+			copies[i].AnnotationID = nil
+		}
+	}
+	return copies
+}
+
+func (b *WorkspaceEditBuilder) supports(kind ResourceOperationKind) bool {
+	if b.err != nil {
+		return false
+	}
+	for _, k := range b.capabilities.ResourceOperations {
+		if k == kind {
+			return true
+		}
+	}
+	b.err = fmt.Errorf("client does not support the %s resource operation", kind)
+	return false
+}
+
+func sameVersion(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+//validateEdits checks that the ranges of edits to the same document do not overlap.
+//Insertions at the same position are allowed and are applied in the order given
+func validateEdits(edits []TextEdit) error {
+	sorted := make([]TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Range, sorted[j].Range
+		if a.Start == b.Start {
+			//insertions go before replacements starting at the same position
+			return a.Start == a.End && b.Start != b.End
+		}
+		return a.Start.Before(b.Start)
+	})
+	for i := 1; i < len(sorted); i++ {
+		previous, current := sorted[i-1].Range, sorted[i].Range
+		if current.Start.Before(previous.End) {
+			return fmt.Errorf("edit at %d:%d overlaps edit at %d:%d", current.Start.Line, current.Start.Character,
+				previous.Start.Line, previous.Start.Character)
+		}
+	}
+	for _, edit := range edits {
+		if edit.Range.End.Before(edit.Range.Start) {
+			return fmt.Errorf("edit at %d:%d ends before it starts", edit.Range.Start.Line, edit.Range.Start.Character)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package lsp
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/adedayo/go-lsp/pkg/code"
+)
+
+func edit(r code.Range, text string) TextEdit {
+	return TextEdit{Range: r, NewText: text}
+}
+
+func TestValidateEdits(t *testing.T) {
+	tests := []struct {
+		name  string
+		edits []TextEdit
+		err   string
+	}{
+		{name: "none"},
+		{name: "disjoint", edits: []TextEdit{edit(rng(1, 0, 1, 2), "a"), edit(rng(0, 0, 0, 5), "b")}},
+		{name: "touching", edits: []TextEdit{edit(rng(0, 0, 0, 2), "a"), edit(rng(0, 2, 0, 4), "b")}},
+		{name: "insertions at the same position", edits: []TextEdit{edit(rng(0, 1, 0, 1), "a"), edit(rng(0, 1, 0, 1), "b")}},
+		{name: "insertion at the start of a replacement", edits: []TextEdit{edit(rng(0, 1, 0, 3), "a"), edit(rng(0, 1, 0, 1), "b")}},
+		{name: "insertion inside a replacement", edits: []TextEdit{edit(rng(0, 1, 0, 3), "a"), edit(rng(0, 2, 0, 2), "b")}, err: "overlaps"},
+		{name: "overlapping", edits: []TextEdit{edit(rng(0, 0, 1, 0), "a"), edit(rng(0, 5, 2, 0), "b")}, err: "overlaps"},
+		{name: "same range", edits: []TextEdit{edit(rng(0, 0, 0, 1), "a"), edit(rng(0, 0, 0, 1), "b")}, err: "overlaps"},
+		{name: "inverted", edits: []TextEdit{edit(rng(1, 0, 0, 0), "a")}, err: "ends before it starts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEdits(tt.edits)
+			if tt.err == "" && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func allResourceOperations() *WorkspaceEditClientCapabilities {
+	yes := true
+	return &WorkspaceEditClientCapabilities{
+		DocumentChanges:    &yes,
+		ResourceOperations: []ResourceOperationKind{ResourceOperationCreate, ResourceOperationRename, ResourceOperationDelete},
+	}
+}
+
+//describe summarises the document changes of an edit, e.g. "edit a.dsl(2)" or "rename a.dsl b.dsl"
+func describe(edit *WorkspaceEdit) []string {
+	summary := []string{}
+	for _, change := range edit.DocumentChanges {
+		switch {
+		case change.TextDocumentEdit != nil:
+			summary = append(summary, "edit "+string(change.TextDocumentEdit.TextDocument.URI)+strings.Repeat("+", len(change.TextDocumentEdit.Edits)))
+		case change.CreateFile != nil:
+			summary = append(summary, "create "+string(change.CreateFile.URI))
+		case change.RenameFile != nil:
+			summary = append(summary, "rename "+string(change.RenameFile.OldURI)+" "+string(change.RenameFile.NewURI))
+		case change.DeleteFile != nil:
+			summary = append(summary, "delete "+string(change.DeleteFile.URI))
+		}
+	}
+	return summary
+}
+
+func TestWorkspaceEditBuilderOrdersResourceOperations(t *testing.T) {
+	version := int64(3)
+	edit, err := NewWorkspaceEditBuilder(allResourceOperations()).
+		Edit("a", &version, edit(rng(0, 0, 0, 1), "x")).
+		CreateFile("b", nil).
+		Edit("b", nil, edit(rng(0, 0, 0, 0), "new")).
+		Edit("a", &version, edit(rng(1, 0, 1, 1), "y")).
+		RenameFile("a", "c", nil).
+		Edit("c", nil, edit(rng(2, 0, 2, 0), "z")).
+		DeleteFile("b", nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"edit a++", "create b", "edit b+", "rename a c", "edit c+", "delete b"}
+	if got := describe(edit); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if edit.Changes != nil {
+		t.Errorf("changes must not be mixed with document changes: %v", edit.Changes)
+	}
+}
+
+func TestWorkspaceEditBuilderErrors(t *testing.T) {
+	v1, v2 := int64(1), int64(2)
+	tests := []struct {
+		name         string
+		capabilities *WorkspaceEditClientCapabilities
+		build        func(b *WorkspaceEditBuilder) *WorkspaceEditBuilder
+		err          string
+	}{
+		{
+			name:         "edit after delete",
+			capabilities: allResourceOperations(),
+			build: func(b *WorkspaceEditBuilder) *WorkspaceEditBuilder {
+				return b.DeleteFile("a", nil).Edit("a", nil, edit(rng(0, 0, 0, 0), "x"))
+			},
+			err: "after a delete operation",
+		},
+		{
+			name:         "edit after rename",
+			capabilities: allResourceOperations(),
+			build: func(b *WorkspaceEditBuilder) *WorkspaceEditBuilder {
+				return b.RenameFile("a", "b", nil).Edit("a", nil, edit(rng(0, 0, 0, 0), "x"))
+			},
+			err: "after a rename operation",
+		},
+		{
+			name:         "conflicting versions",
+			capabilities: allResourceOperations(),
+			build: func(b *WorkspaceEditBuilder) *WorkspaceEditBuilder {
+				return b.Edit("a", &v1, edit(rng(0, 0, 0, 0), "x")).Edit("a", &v2, edit(rng(1, 0, 1, 0), "y"))
+			},
+			err: "conflicting versions",
+		},
+		{
+			name:         "overlapping edits",
+			capabilities: allResourceOperations(),
+			build: func(b *WorkspaceEditBuilder) *WorkspaceEditBuilder {
+				return b.Edit("a", nil, edit(rng(0, 0, 0, 4), "x")).Edit("a", nil, edit(rng(0, 2, 0, 6), "y"))
+			},
+			err: "overlaps",
+		},
+		{
+			name: "unsupported resource operation",
+			build: func(b *WorkspaceEditBuilder) *WorkspaceEditBuilder {
+				return b.CreateFile("a", nil)
+			},
+			err: "does not support the create resource operation",
+		},
+		{
+			name: "edits interleaved with resource operations without document changes",
+			capabilities: &WorkspaceEditClientCapabilities{
+				ResourceOperations: []ResourceOperationKind{ResourceOperationCreate},
+			},
+			build: func(b *WorkspaceEditBuilder) *WorkspaceEditBuilder {
+				return b.Edit("a", nil, edit(rng(0, 0, 0, 0), "x")).CreateFile("a", nil).Edit("a", nil, edit(rng(0, 0, 0, 0), "y"))
+			},
+			err: "without document changes",
+		},
+		{
+			name:         "unknown annotation",
+			capabilities: allResourceOperations(),
+			build: func(b *WorkspaceEditBuilder) *WorkspaceEditBuilder {
+				id := ChangeAnnotationIdentifier("missing")
+				return b.Edit("a", nil, TextEdit{NewText: "x", AnnotationID: &id})
+			},
+			err: "unknown change annotation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build(NewWorkspaceEditBuilder(tt.capabilities)).Build()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want one containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWorkspaceEditBuilderChanges(t *testing.T) {
+	version := int64(7)
+	edit, err := NewWorkspaceEditBuilder(nil).
+		Edit("a", &version, edit(rng(0, 0, 0, 1), "x")).
+		Edit("b", nil, edit(rng(0, 0, 0, 0), "y")).
+		Edit("a", &version, edit(rng(1, 0, 1, 0), "z")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edit.DocumentChanges != nil {
+		t.Errorf("document changes sent to a client without support for them")
+	}
+	if len(edit.Changes["a"]) != 2 || len(edit.Changes["b"]) != 1 {
+		t.Errorf("got changes %v", edit.Changes)
+	}
+}
+
+func TestDocumentChangeJSON(t *testing.T) {
+	edit, err := NewWorkspaceEditBuilder(allResourceOperations()).
+		CreateFile("a", nil).
+		Edit("a", nil, edit(rng(0, 0, 0, 0), "x")).
+		RenameFile("a", "b", nil).
+		DeleteFile("b", nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	js, err := json.Marshal(edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := WorkspaceEdit{}
+	decode(t, js, &decoded)
+	if got, want := describe(&decoded), describe(edit); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip through %s gave %q, want %q", js, got, want)
+	}
+}
+
+//annotations lists the annotation of each document change of an edit, or "-" for changes without one
+func annotations(edit *WorkspaceEdit) []string {
+	ids := []string{}
+	add := func(id *ChangeAnnotationIdentifier) {
+		if id == nil {
+			ids = append(ids, "-")
+		} else {
+			ids = append(ids, string(*id))
+		}
+	}
+	for _, change := range edit.DocumentChanges {
+		switch {
+		case change.TextDocumentEdit != nil:
+			for _, edit := range change.TextDocumentEdit.Edits {
+				add(edit.AnnotationID)
+			}
+		case change.CreateFile != nil:
+			add(change.CreateFile.AnnotationID)
+		case change.RenameFile != nil:
+			add(change.RenameFile.AnnotationID)
+		case change.DeleteFile != nil:
+			add(change.DeleteFile.AnnotationID)
+		}
+	}
+	return ids
+}
+
+func annotatedBuilder(annotationSupport bool) *WorkspaceEditBuilder {
+	capabilities := allResourceOperations()
+	if annotationSupport {
+		capabilities.ChangeAnnotationSupport = &changeAnnotationSupport{}
+	}
+	confirm := ChangeAnnotationIdentifier("confirm")
+	return NewWorkspaceEditBuilder(capabilities).
+		Annotate("confirm", ChangeAnnotation{Label: "Confirm"}).
+		AnnotatedCreateFile("a", nil, "confirm").
+		Edit("a", nil, TextEdit{Range: rng(0, 0, 0, 0), NewText: "x", AnnotationID: &confirm}).
+		AnnotatedRenameFile("a", "b", nil, "confirm").
+		AnnotatedDeleteFile("c", nil, "confirm").
+		DeleteFile("d", nil)
+}
+
+func TestWorkspaceEditBuilderAnnotations(t *testing.T) {
+	edit, err := annotatedBuilder(true).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := annotations(edit), []string{"confirm", "confirm", "confirm", "confirm", "-"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got annotations %q, want %q", got, want)
+	}
+	if _, ok := edit.ChangeAnnotations["confirm"]; !ok || len(edit.ChangeAnnotations) != 1 {
+		t.Errorf("got change annotations %v", edit.ChangeAnnotations)
+	}
+
+	edit, err = annotatedBuilder(false).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := annotations(edit), []string{"-", "-", "-", "-", "-"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got annotations %q for a client without annotation support, want %q", got, want)
+	}
+	if edit.ChangeAnnotations != nil {
+		t.Errorf("got change annotations %v for a client without annotation support", edit.ChangeAnnotations)
+	}
+}
+
+func TestWorkspaceEditBuilderBuildsRepeatedly(t *testing.T) {
+	for _, support := range []bool{true, false} {
+		b := annotatedBuilder(support)
+		first, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("building twice with annotation support %v gave %+v, then %+v", support, first, second)
+		}
+		b.Edit("b", nil, edit(rng(1, 0, 1, 0), "y"))
+		if !reflect.DeepEqual(first, second) || len(describe(first)) != 5 {
+			t.Errorf("extending the builder changed an edit it built: %q", describe(first))
+		}
+	}
+}
+
+//renameServer renames with an annotated edit
+type renameServer struct {
+	*testServer
+}
+
+func (s *renameServer) Rename(params *RenameParams) (*WorkspaceEdit, error) {
+	return s.NewWorkspaceEditBuilder().
+		Annotate("confirm", ChangeAnnotation{Label: "Rename in comments"}).
+		AnnotatedRenameFile(params.TextDocument.URI, "file:///b.dsl", nil, "confirm").
+		Build()
+}
+
+func TestRenameHonorsChangeAnnotations(t *testing.T) {
+	for _, honors := range []bool{true, false} {
+		s := &renameServer{newTestServer()}
+		c := startTestServer(t, s.DefaultServer, s)
+		c.initialize(`{"capabilities":{
+			"workspace":{"workspaceEdit":{"documentChanges":true,"resourceOperations":["rename"],"changeAnnotationSupport":{}}},
+			"textDocument":{"rename":{"honorsChangeAnnotations":` + strconv.FormatBool(honors) + `}}}}`)
+		c.request(1, "textDocument/rename", map[string]interface{}{
+			"textDocument": map[string]string{"uri": "file:///a.dsl"},
+			"position":     map[string]int{"line": 0, "character": 0},
+			"newName":      "b",
+		})
+		response := c.next()
+		edit := WorkspaceEdit{}
+		decode(t, response.Result, &edit)
+		want := []string{"-"}
+		if honors {
+			want = []string{"confirm"}
+		}
+		if got := annotations(&edit); !reflect.DeepEqual(got, want) || (edit.ChangeAnnotations != nil) != honors {
+			t.Errorf("client honoring annotations %v got %s", honors, response.Result)
+		}
+	}
+}
+
+//confirmingRenameServer renames in code right away, but asks for confirmation to rename in comments and to rename the file
+type confirmingRenameServer struct {
+	*testServer
+}
+
+func (s *confirmingRenameServer) Rename(params *RenameParams) (*WorkspaceEdit, error) {
+	confirm, inCode := ChangeAnnotationIdentifier("comments"), ChangeAnnotationIdentifier("code")
+	needsConfirmation := true
+	return s.NewWorkspaceEditBuilder().
+		Annotate(inCode, ChangeAnnotation{Label: "Rename in code"}).
+		Annotate(confirm, ChangeAnnotation{Label: "Rename in comments", NeedsConfirmation: &needsConfirmation}).
+		Edit(params.TextDocument.URI, nil,
+			TextEdit{Range: rng(0, 0, 0, 1), NewText: params.NewName, AnnotationID: &inCode},
+			TextEdit{Range: rng(1, 3, 1, 4), NewText: params.NewName, AnnotationID: &confirm}).
+		Edit("file:///c.dsl", nil, TextEdit{Range: rng(2, 0, 2, 1), NewText: params.NewName, AnnotationID: &confirm}).
+		AnnotatedRenameFile(params.TextDocument.URI, "file:///b.dsl", nil, confirm).
+		Build()
+}
+
+func TestRenameLeavesOutUnconfirmedChanges(t *testing.T) {
+	for _, honors := range []bool{true, false} {
+		s := &confirmingRenameServer{newTestServer()}
+		c := startTestServer(t, s.DefaultServer, s)
+		c.initialize(`{"capabilities":{
+			"workspace":{"workspaceEdit":{"documentChanges":true,"resourceOperations":["rename"],"changeAnnotationSupport":{}}},
+			"textDocument":{"rename":{"honorsChangeAnnotations":` + strconv.FormatBool(honors) + `}}}}`)
+		c.request(1, "textDocument/rename", map[string]interface{}{
+			"textDocument": map[string]string{"uri": "file:///a.dsl"},
+			"position":     map[string]int{"line": 0, "character": 0},
+			"newName":      "b",
+		})
+		response := c.next()
+		edit := WorkspaceEdit{}
+		decode(t, response.Result, &edit)
+		if honors {
+			if got, want := annotations(&edit), []string{"code", "comments", "comments", "comments"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got annotations %q for a client honoring them, want %q", got, want)
+			}
+			continue
+		}
+		if edit.ChangeAnnotations != nil {
+			t.Errorf("got change annotations %v for a client not honoring them", edit.ChangeAnnotations)
+		}
+		if got, want := annotations(&edit), []string{"-"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got annotations %q, want %q", got, want)
+		}
+		if len(edit.DocumentChanges) != 1 || edit.DocumentChanges[0].TextDocumentEdit == nil ||
+			!reflect.DeepEqual(edit.DocumentChanges[0].TextDocumentEdit.Edits, []TextEdit{{Range: rng(0, 0, 0, 1), NewText: "b"}}) {
+			t.Errorf("got %s, want only the edit in code", response.Result)
+		}
+	}
+}
+
+func TestWithoutAnnotationsChanges(t *testing.T) {
+	needsConfirmation := true
+	confirm, plain := ChangeAnnotationIdentifier("confirm"), ChangeAnnotationIdentifier("plain")
+	edit := &WorkspaceEdit{
+		Changes: map[code.DocumentURI][]TextEdit{
+			"file:///a.dsl": {
+				{Range: rng(0, 0, 0, 1), NewText: "x", AnnotationID: &plain},
+				{Range: rng(1, 0, 1, 1), NewText: "y", AnnotationID: &confirm},
+			},
+			"file:///b.dsl": {{Range: rng(0, 0, 0, 1), NewText: "z", AnnotationID: &confirm}},
+		},
+		ChangeAnnotations: map[ChangeAnnotationIdentifier]ChangeAnnotation{
+			confirm: {Label: "Confirm", NeedsConfirmation: &needsConfirmation},
+			plain:   {Label: "Plain"},
+		},
+	}
+	want := map[code.DocumentURI][]TextEdit{"file:///a.dsl": {{Range: rng(0, 0, 0, 1), NewText: "x"}}}
+	if got := withoutAnnotations(edit); !reflect.DeepEqual(got.Changes, want) || got.ChangeAnnotations != nil {
+		t.Errorf("got %+v, want changes %+v", got, want)
+	}
+	if edit.Changes["file:///a.dsl"][0].AnnotationID == nil || len(edit.Changes["file:///b.dsl"]) != 1 {
+		t.Errorf("the original edit was modified: %+v", edit)
+	}
+}